@@ -0,0 +1,243 @@
+package validor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// OutputAssertion checks a terraform output after apply. Path selects a value
+// inside the output with a JSONPath subset such as $.subnets[0].name.
+type OutputAssertion struct {
+	Output   string `yaml:"output"`
+	Path     string `yaml:"path"`
+	Equals   any    `yaml:"equals"`
+	Matches  string `yaml:"matches"`
+	Length   *int   `yaml:"length"`
+	NotEmpty bool   `yaml:"not_empty"`
+}
+
+func (a OutputAssertion) validate() error {
+	if a.Output == "" {
+		return errors.New("assertion without output")
+	}
+	if a.Equals == nil && a.Matches == "" && a.Length == nil && !a.NotEmpty {
+		return fmt.Errorf("assertion on output %s has no equals, matches, length or not_empty", a.Output)
+	}
+	if a.Matches != "" {
+		if _, err := regexp.Compile(a.Matches); err != nil {
+			return fmt.Errorf("assertion on output %s: invalid matches: %w", a.Output, err)
+		}
+	}
+	if _, err := selectPath(nil, a.Path, true); err != nil {
+		return fmt.Errorf("assertion on output %s: %w", a.Output, err)
+	}
+	return nil
+}
+
+func (a OutputAssertion) label() string {
+	if a.Path == "" {
+		return "output " + a.Output
+	}
+	return "output " + a.Output + " at " + a.Path
+}
+
+// check returns one error per failed check of the assertion.
+func (a OutputAssertion) check(outputs map[string]any) []error {
+	value, ok := outputs[a.Output]
+	if !ok {
+		return []error{fmt.Errorf("output %s does not exist", a.Output)}
+	}
+	value, err := selectPath(value, a.Path, false)
+	if err != nil {
+		return []error{fmt.Errorf("%s: %w", a.label(), err)}
+	}
+
+	var errs []error
+	if a.Equals != nil {
+		if expected := normalizeJSON(a.Equals); !reflect.DeepEqual(value, expected) {
+			errs = append(errs, fmt.Errorf("%s: expected %s, got %s", a.label(), formatJSON(expected), formatJSON(value)))
+		}
+	}
+	if a.Matches != "" {
+		text, ok := value.(string)
+		if !ok {
+			text = formatJSON(value)
+		}
+		if re, err := regexp.Compile(a.Matches); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid matches: %w", a.label(), err))
+		} else if !re.MatchString(text) {
+			errs = append(errs, fmt.Errorf("%s: expected a match for %q, got %s", a.label(), a.Matches, formatJSON(value)))
+		}
+	}
+	if a.Length != nil {
+		length, ok := valueLength(value)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: expected length %d, got %s which has no length", a.label(), *a.Length, formatJSON(value)))
+		} else if length != *a.Length {
+			errs = append(errs, fmt.Errorf("%s: expected length %d, got %d", a.label(), *a.Length, length))
+		}
+	}
+	if a.NotEmpty {
+		if length, ok := valueLength(value); value == nil || (ok && length == 0) {
+			errs = append(errs, fmt.Errorf("%s: expected a non-empty value, got %s", a.label(), formatJSON(value)))
+		}
+	}
+	return errs
+}
+
+// AssertOutputs reads the outputs of the module and evaluates the assertions
+// of its manifest. Every failed check is recorded as its own error.
+func (m *Module) AssertOutputs(ctx context.Context, t *testing.T) error {
+	t.Helper()
+
+	if m.Manifest == nil || len(m.Manifest.Assertions) == 0 {
+		return nil
+	}
+
+	t.Logf("Checking %d output assertion(s) of module %s", len(m.Manifest.Assertions), m.Name)
+
+	outputs, err := readOutputs(ctx, t, m)
+	if err != nil {
+		return m.recordError(t, "output assertion", err)
+	}
+
+	var failed error
+	for _, assertion := range m.Manifest.Assertions {
+		for _, err := range assertion.check(outputs) {
+			failed = m.recordError(t, "output assertion", err)
+		}
+	}
+	return failed
+}
+
+// selectPath follows path, a JSONPath subset of .key, ['key'] and [index]
+// steps with an optional leading $. With parseOnly the path is only checked.
+func selectPath(value any, path string, parseOnly bool) (any, error) {
+	rest := strings.TrimPrefix(path, "$")
+	if rest != "" && rest[0] != '.' && rest[0] != '[' {
+		rest = "." + rest
+	}
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			rest = rest[end:]
+			if key == "" {
+				return nil, fmt.Errorf("invalid path %q: empty key", path)
+			}
+			if !parseOnly {
+				var err error
+				if value, err = selectKey(value, key); err != nil {
+					return nil, err
+				}
+			}
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: missing ]", path)
+			}
+			step := rest[1:end]
+			rest = rest[end+1:]
+			if key, ok := unquotePathKey(step); ok {
+				if !parseOnly {
+					var err error
+					if value, err = selectKey(value, key); err != nil {
+						return nil, err
+					}
+				}
+				continue
+			}
+			index, err := strconv.Atoi(step)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid path %q: bad index %q", path, step)
+			}
+			if !parseOnly {
+				if value, err = selectIndex(value, index); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			return nil, fmt.Errorf("invalid path %q: unexpected %q", path, rest[0])
+		}
+	}
+	return value, nil
+}
+
+func unquotePathKey(step string) (string, bool) {
+	if len(step) >= 2 && (step[0] == '\'' || step[0] == '"') && step[len(step)-1] == step[0] {
+		return step[1 : len(step)-1], true
+	}
+	return "", false
+}
+
+func selectKey(value any, key string) (any, error) {
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("cannot select key %q from %s", key, formatJSON(value))
+	}
+	selected, ok := object[key]
+	if !ok {
+		return nil, fmt.Errorf("key %q does not exist", key)
+	}
+	return selected, nil
+}
+
+func selectIndex(value any, index int) (any, error) {
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("cannot select index %d from %s", index, formatJSON(value))
+	}
+	if index >= len(list) {
+		return nil, fmt.Errorf("index %d out of range, length is %d", index, len(list))
+	}
+	return list[index], nil
+}
+
+func valueLength(value any) (int, bool) {
+	switch v := value.(type) {
+	case []any:
+		return len(v), true
+	case map[string]any:
+		return len(v), true
+	case string:
+		return utf8.RuneCountInString(v), true
+	default:
+		return 0, false
+	}
+}
+
+// normalizeJSON converts a value decoded from YAML to the types produced by
+// encoding/json, so it compares equal to a decoded terraform output.
+func normalizeJSON(value any) any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+func formatJSON(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,166 @@
+package validor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSelectPath(t *testing.T) {
+	value := map[string]any{
+		"subnets": []any{
+			map[string]any{"name": "snet-a", "address.prefix": "10.0.1.0/24"},
+		},
+	}
+
+	tests := []struct {
+		path    string
+		want    any
+		wantErr bool
+	}{
+		{"", value, false},
+		{"$.subnets[0].name", "snet-a", false},
+		{"subnets[0].name", "snet-a", false},
+		{"$.subnets[0]['address.prefix']", "10.0.1.0/24", false},
+		{"$.subnets[1]", nil, true},
+		{"$.missing", nil, true},
+		{"$.subnets.name", nil, true},
+		{"$.subnets[x]", nil, true},
+		{"$..name", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := selectPath(value, tt.path, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && formatJSON(got) != formatJSON(tt.want) {
+				t.Errorf("selectPath() = %s, want %s", formatJSON(got), formatJSON(tt.want))
+			}
+		})
+	}
+}
+
+func TestOutputAssertion_Check(t *testing.T) {
+	outputs := map[string]any{
+		"name":    "rg-test",
+		"count":   float64(3),
+		"subnets": []any{map[string]any{"name": "snet-a"}, map[string]any{"name": "snet-b"}},
+		"tags":    map[string]any{"env": "test"},
+		"empty":   "",
+	}
+
+	tests := []struct {
+		name      string
+		assertion OutputAssertion
+		wantErrs  []string
+	}{
+		{"equal string", OutputAssertion{Output: "name", Equals: "rg-test"}, nil},
+		{"equal number from yaml", OutputAssertion{Output: "count", Equals: 3}, nil},
+		{"equal map", OutputAssertion{Output: "tags", Equals: map[string]any{"env": "test"}}, nil},
+		{"not equal", OutputAssertion{Output: "name", Equals: "rg-prod"}, []string{`expected "rg-prod", got "rg-test"`}},
+		{"matches", OutputAssertion{Output: "name", Matches: "^rg-"}, nil},
+		{"does not match", OutputAssertion{Output: "name", Matches: "^st"}, []string{`expected a match for "^st"`}},
+		{"length", OutputAssertion{Output: "subnets", Length: ptr(2)}, nil},
+		{"wrong length", OutputAssertion{Output: "subnets", Length: ptr(3)}, []string{"expected length 3, got 2"}},
+		{"path", OutputAssertion{Output: "subnets", Path: "$[1].name", Equals: "snet-b"}, nil},
+		{"not empty", OutputAssertion{Output: "name", NotEmpty: true}, nil},
+		{"empty", OutputAssertion{Output: "empty", NotEmpty: true}, []string{"expected a non-empty value"}},
+		{"missing output", OutputAssertion{Output: "missing", NotEmpty: true}, []string{"output missing does not exist"}},
+		{
+			"each failed check is reported",
+			OutputAssertion{Output: "name", Equals: "x", Length: ptr(1)},
+			[]string{`expected "x"`, "expected length 1, got 7"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.assertion.check(outputs)
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("check() = %v, want %d error(s)", errs, len(tt.wantErrs))
+			}
+			for i, want := range tt.wantErrs {
+				if !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("check() error = %q, want it to contain %q", errs[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadManifest_InvalidAssertions(t *testing.T) {
+	tests := map[string]string{
+		"no check":      "assertions:\n  - output: name\n",
+		"no output":     "assertions:\n  - equals: x\n",
+		"invalid regex": "assertions:\n  - output: name\n    matches: '('\n",
+		"invalid path":  "assertions:\n  - output: name\n    path: '$.a['\n    not_empty: true\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, ManifestFileName), content)
+			if _, err := LoadManifest(dir); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestModule_AssertOutputs(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ManifestFileName), `
+assertions:
+  - output: name
+    equals: rg-test
+  - output: subnets
+    length: 3
+  - output: subnets
+    path: $[0]
+    matches: ^snet-
+`)
+	module := NewModule("example", dir)
+	module.Options.TerraformBinary = writeScript(t, `echo '{"name":{"value":"rg-test"},"subnets":{"value":["snet-a","snet-b"]}}'`+"\n")
+	if err := module.LoadManifest(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := module.AssertOutputs(t.Context(), t)
+	var moduleErr *ModuleError
+	if !errors.As(err, &moduleErr) || moduleErr.Operation != "output assertion" {
+		t.Fatalf("expected an output assertion error, got %v", err)
+	}
+	if len(module.Errors) != 1 || !strings.Contains(module.Errors[0].Error(), "expected length 3, got 2") {
+		t.Errorf("Errors = %v, want one length error", module.Errors)
+	}
+}
+
+func TestRunModuleTests_OutputAssertions(t *testing.T) {
+	callLog := filepath.Join(t.TempDir(), "calls")
+	script := writeScript(t, `echo "$1" >> `+callLog+`
+[ "$1" = output ] && echo '{"name":{"value":"rg-test"}}'
+exit 0
+`)
+	t.Setenv("PATH", filepath.Dir(script)+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ManifestFileName), "assertions:\n  - output: name\n    equals: rg-test\n")
+	module := NewModule("example", dir)
+	module.RetryPolicy = &RetryPolicy{}
+
+	t.Run("examples", func(t *testing.T) {
+		runModuleTests(t, []*Module{module}, false, NewConfig(WithSkipDestroy(true)), nil, "registry")
+	})
+
+	if len(module.Errors) != 0 {
+		t.Errorf("Errors = %v, want none", module.Errors)
+	}
+	calls, err := os.ReadFile(callLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "version\ninit\napply\noutput\n"; string(calls) != want {
+		t.Errorf("terraform calls = %q, want %q", calls, want)
+	}
+}
@@ -0,0 +1,180 @@
+package validor
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// changedFiles returns the absolute paths of the files in the git repository
+// around dir that changed since the merge base of ref and HEAD, including
+// uncommitted and untracked files.
+func changedFiles(dir, ref string) ([]string, error) {
+	toplevel, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	toplevel = strings.TrimSpace(toplevel)
+
+	base, err := git(dir, "merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	diff, err := git(toplevel, "diff", "--name-only", "--no-renames", strings.TrimSpace(base), "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := git(toplevel, "ls-files", "--others", "--exclude-standard", "--full-name")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for line := range strings.Lines(diff + untracked) {
+		if name := strings.TrimSpace(line); name != "" {
+			files = append(files, filepath.Join(toplevel, filepath.FromSlash(name)))
+		}
+	}
+	return files, nil
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return string(output), nil
+}
+
+// moduleRootDir returns the module root of an examples directory, the nearest
+// directory above it with terraform files, or its parent when there is none.
+func moduleRootDir(examplesPath string) string {
+	parent := filepath.Dir(examplesPath)
+	for dir := parent; dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if hasTerraformFiles(dir) {
+			return dir
+		}
+	}
+	return parent
+}
+
+// isModuleRootChange reports whether file is a *.tf file in the module root
+// or anything below its modules directory.
+func isModuleRootChange(root, file string) bool {
+	if filepath.Dir(file) == root && filepath.Ext(file) == ".tf" {
+		return true
+	}
+	return isWithin(filepath.Join(root, "modules"), file)
+}
+
+func isWithin(dir, file string) bool {
+	rel, err := filepath.Rel(dir, file)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// realPath makes path absolute and resolves symlinks, so it compares equal to
+// the paths reported by git.
+func realPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved, nil
+	}
+	return abs, nil
+}
+
+// filterModulesByChanges keeps the modules whose files changed since
+// config.ChangedSince and the modules they depend on. All modules are kept
+// when the module root changed.
+func filterModulesByChanges(modules []*Module, config *Config, examplesPath string) ([]*Module, error) {
+	if config == nil || config.ChangedSince == "" {
+		return modules, nil
+	}
+
+	examplesDir, err := realPath(examplesPath)
+	if err != nil {
+		return nil, err
+	}
+	files, err := changedFiles(examplesDir, config.ChangedSince)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changes since %s: %w", config.ChangedSince, err)
+	}
+
+	root := moduleRootDir(examplesDir)
+	for _, file := range files {
+		if isModuleRootChange(root, file) {
+			rel, _ := filepath.Rel(root, file)
+			fmt.Printf("Selecting all modules as the module root changed since %s: %s\n", config.ChangedSince, filepath.ToSlash(rel))
+			return modules, nil
+		}
+	}
+
+	var selected []*Module
+	for _, module := range modules {
+		modulePath, err := realPath(module.Path)
+		if err != nil {
+			return nil, err
+		}
+		var changed []string
+		for _, file := range files {
+			if isWithin(modulePath, file) {
+				rel, _ := filepath.Rel(modulePath, file)
+				changed = append(changed, filepath.ToSlash(rel))
+			}
+		}
+		if len(changed) == 0 {
+			fmt.Printf("Skipping module %s as nothing changed since %s\n", module.Name, config.ChangedSince)
+			continue
+		}
+		fmt.Printf("Selecting module %s as it changed since %s: %s\n", module.Name, config.ChangedSince, strings.Join(changed, ", "))
+		selected = append(selected, module)
+	}
+	return withDependencies(modules, selected), nil
+}
+
+// withDependencies adds the modules that selected depend on, directly or
+// through other modules, as a dependent cannot run without them.
+func withDependencies(modules, selected []*Module) []*Module {
+	byName := make(map[string]*Module, len(modules))
+	for _, module := range modules {
+		byName[module.Name] = module
+	}
+	included := make(map[string]bool, len(modules))
+	for _, module := range selected {
+		included[module.Name] = true
+	}
+
+	queue := slices.Clone(selected)
+	for len(queue) > 0 {
+		module := queue[0]
+		queue = queue[1:]
+		for _, name := range module.dependsOn() {
+			dependency, ok := byName[name]
+			if !ok || included[name] {
+				continue
+			}
+			fmt.Printf("Selecting module %s as %s depends on it\n", name, module.Name)
+			included[name] = true
+			queue = append(queue, dependency)
+		}
+	}
+
+	var result []*Module
+	for _, module := range modules {
+		if included[module.Name] {
+			result = append(result, module)
+		}
+	}
+	return result
+}
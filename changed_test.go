@@ -0,0 +1,101 @@
+package validor
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "main.tf"), "")
+	writeFile(t, filepath.Join(dir, "modules", "network", "main.tf"), "")
+	writeFile(t, filepath.Join(dir, "README.md"), "")
+	for _, name := range []string{"default", "private", "network/peering"} {
+		writeFile(t, filepath.Join(dir, "examples", filepath.FromSlash(name), "main.tf"), "")
+	}
+	gitCommand(t, dir, "init", "-q")
+	gitCommand(t, dir, "add", "-A")
+	gitCommand(t, dir, "commit", "-q", "-m", "initial")
+	gitCommand(t, dir, "tag", "base")
+	return dir
+}
+
+func gitCommand(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, output)
+	}
+}
+
+func TestFilterModulesByChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []string
+		commit  bool
+		want    string
+	}{
+		{"nothing changed", nil, false, ""},
+		{"committed example change", []string{"examples/private/main.tf"}, true, "private"},
+		{"uncommitted nested example change", []string{"examples/network/peering/variables.tf"}, false, "network/peering"},
+		{"root terraform change", []string{"variables.tf"}, false, "default,private,network/peering"},
+		{"submodule change", []string{"modules/network/outputs.tf"}, true, "default,private,network/peering"},
+		{"unrelated root file", []string{"README.md"}, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := gitRepo(t)
+			for _, change := range tt.changes {
+				writeFile(t, filepath.Join(dir, filepath.FromSlash(change)), "# changed\n")
+			}
+			if tt.commit {
+				gitCommand(t, dir, "add", "-A")
+				gitCommand(t, dir, "commit", "-q", "-m", "change")
+			}
+
+			examplesPath := filepath.Join(dir, "examples")
+			modules := createModulesFromNames([]string{"default", "private", "network/peering"}, examplesPath)
+			selected, err := filterModulesByChanges(modules, NewConfig(WithChangedSince("base")), examplesPath)
+			if err != nil {
+				t.Fatalf("filterModulesByChanges() error = %v", err)
+			}
+			if got := moduleNames(selected); got != tt.want {
+				t.Errorf("filterModulesByChanges() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("dependencies of a changed example", func(t *testing.T) {
+		dir := gitRepo(t)
+		writeFile(t, filepath.Join(dir, "examples", "network", "peering", "main.tf"), "# changed\n")
+
+		examplesPath := filepath.Join(dir, "examples")
+		modules := createModulesFromNames([]string{"default", "private", "network/peering"}, examplesPath)
+		modules[1].Manifest = &Manifest{DependsOn: []string{"default"}}
+		modules[2].Manifest = &Manifest{DependsOn: []string{"private"}}
+		selected, err := filterModulesByChanges(modules, NewConfig(WithChangedSince("base")), examplesPath)
+		if err != nil {
+			t.Fatalf("filterModulesByChanges() error = %v", err)
+		}
+		if got, want := moduleNames(selected), "default,private,network/peering"; got != want {
+			t.Errorf("filterModulesByChanges() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("unknown ref", func(t *testing.T) {
+		dir := gitRepo(t)
+		examplesPath := filepath.Join(dir, "examples")
+		modules := createModulesFromNames([]string{"default"}, examplesPath)
+		if _, err := filterModulesByChanges(modules, NewConfig(WithChangedSince("missing")), examplesPath); err == nil {
+			t.Error("expected an error for an unknown ref")
+		}
+	})
+}
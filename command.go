@@ -0,0 +1,150 @@
+package validor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// terraformGracePeriod is how long a cancelled terraform process gets to
+// handle the interrupt and release its state lock before it is killed.
+const terraformGracePeriod = 30 * time.Second
+
+var commandsWithParallelism = []string{"plan", "apply", "destroy"}
+
+type TerraformCommandError struct {
+	Binary   string
+	Args     []string
+	ExitCode int
+	Stderr   string
+	Err      error
+}
+
+func (e *TerraformCommandError) Error() string {
+	msg := fmt.Sprintf("%s %s failed: %v", e.Binary, strings.Join(e.Args, " "), e.Err)
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		msg += "\n" + stderr
+	}
+	return msg
+}
+
+func (e *TerraformCommandError) Unwrap() error {
+	return e.Err
+}
+
+func runTerraform(ctx context.Context, t testLogger, options *terraform.Options, args ...string) (string, error) {
+	t.Helper()
+
+	binary := options.TerraformBinary
+	if binary == "" {
+		binary = defaultTerraformBinary
+	}
+	if options.Parallelism > 0 && len(args) > 0 && slices.Contains(commandsWithParallelism, args[0]) {
+		args = append(args, fmt.Sprintf("-parallelism=%d", options.Parallelism))
+	}
+
+	t.Logf("Running command %s with args %v", binary, args)
+
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Dir = options.TerraformDir
+	cmd.Env = os.Environ()
+	for _, key := range slices.Sorted(maps.Keys(options.EnvVars)) {
+		cmd.Env = append(cmd.Env, key+"="+options.EnvVars[key])
+	}
+	cmd.Stdin = options.Stdin
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = terraformGracePeriod
+
+	var stdout, stderr bytes.Buffer
+	logWriter := &lineLogger{t: t}
+	cmd.Stdout = io.MultiWriter(&stdout, logWriter)
+	cmd.Stderr = io.MultiWriter(&stderr, logWriter)
+
+	err := cmd.Run()
+	logWriter.Flush()
+	if err == nil {
+		return stdout.String(), nil
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = fmt.Errorf("%w (%v)", ctxErr, err)
+	}
+
+	cmdErr := &TerraformCommandError{Binary: binary, Args: args, ExitCode: -1, Stderr: stderr.String(), Err: err}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		cmdErr.ExitCode = exitErr.ExitCode()
+	}
+	return stdout.String(), cmdErr
+}
+
+func initArgs(options *terraform.Options) []string {
+	args := []string{"init", fmt.Sprintf("-upgrade=%t", options.Upgrade), "-input=false"}
+	if options.Reconfigure {
+		args = append(args, "-reconfigure")
+	}
+	if options.NoColor {
+		args = append(args, "-no-color")
+	}
+	args = append(args, terraform.FormatTerraformBackendConfigAsArgs(options.BackendConfig)...)
+	args = append(args, terraform.FormatTerraformPluginDirAsArgs(options.PluginDir)...)
+	return append(args, options.ExtraArgs.Init...)
+}
+
+func applyArgs(options *terraform.Options) []string {
+	return terraform.FormatArgs(options, append([]string{"apply", "-input=false", "-auto-approve"}, options.ExtraArgs.Apply...)...)
+}
+
+func destroyArgs(options *terraform.Options) []string {
+	return terraform.FormatArgs(options, append([]string{"destroy", "-auto-approve", "-input=false"}, options.ExtraArgs.Destroy...)...)
+}
+
+func planArgs(options *terraform.Options) []string {
+	return terraform.FormatArgs(options, append([]string{"plan", "-input=false", "-detailed-exitcode"}, options.ExtraArgs.Plan...)...)
+}
+
+// lineLogger forwards command output to the test log one line at a time.
+type lineLogger struct {
+	mu  sync.Mutex
+	t   testLogger
+	buf []byte
+}
+
+func (l *lineLogger) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buf = append(l.buf, p...)
+	for {
+		i := bytes.IndexByte(l.buf, '\n')
+		if i < 0 {
+			break
+		}
+		l.t.Log(string(l.buf[:i]))
+		l.buf = l.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+func (l *lineLogger) Flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.buf) > 0 {
+		l.t.Log(string(l.buf))
+		l.buf = nil
+	}
+}
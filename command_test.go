@@ -0,0 +1,106 @@
+package validor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	return writeScriptAt(t, filepath.Join(t.TempDir(), "terraform"), body)
+}
+
+// writeScriptAt writes an executable shell script with body to path.
+func writeScriptAt(t *testing.T, path, body string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatalf("Failed to create script: %v", err)
+	}
+	return path
+}
+
+func TestRunTerraform(t *testing.T) {
+	t.Run("returns stdout and logs output", func(t *testing.T) {
+		mock := &mockTB{}
+		options := &terraform.Options{
+			TerraformBinary: writeScript(t, `echo "out $1 $VALIDOR_TEST"; echo "warn" >&2`),
+			TerraformDir:    t.TempDir(),
+			EnvVars:         map[string]string{"VALIDOR_TEST": "set"},
+		}
+
+		stdout, err := runTerraform(context.Background(), mock, options, "plan")
+		if err != nil {
+			t.Fatalf("runTerraform() error = %v", err)
+		}
+		if strings.TrimSpace(stdout) != "out plan set" {
+			t.Errorf("stdout = %q", stdout)
+		}
+		joined := strings.Join(mock.logs, "\n")
+		if !strings.Contains(joined, "out plan set") || !strings.Contains(joined, "warn") {
+			t.Errorf("expected output to be logged, got %q", joined)
+		}
+	})
+
+	t.Run("reports exit code and stderr", func(t *testing.T) {
+		options := &terraform.Options{
+			TerraformBinary: writeScript(t, `echo "Error: Conflict" >&2; exit 2`),
+			TerraformDir:    t.TempDir(),
+		}
+
+		_, err := runTerraform(context.Background(), &mockTB{}, options, "plan")
+		var cmdErr *TerraformCommandError
+		if !errors.As(err, &cmdErr) {
+			t.Fatalf("expected TerraformCommandError, got %v", err)
+		}
+		if cmdErr.ExitCode != 2 || !strings.Contains(err.Error(), "Error: Conflict") {
+			t.Errorf("unexpected command error: %v (exit %d)", err, cmdErr.ExitCode)
+		}
+	})
+
+	t.Run("interrupts the process when the context expires", func(t *testing.T) {
+		options := &terraform.Options{
+			TerraformBinary: writeScript(t, "exec sleep 30\n"),
+			TerraformDir:    t.TempDir(),
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := runTerraform(ctx, &mockTB{}, options, "apply")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, got %v", err)
+		}
+		if time.Since(start) > 10*time.Second {
+			t.Errorf("terraform process was not cancelled in time")
+		}
+	})
+}
+
+func TestModule_ApplyTimeout(t *testing.T) {
+	module := NewModule("slow", t.TempDir())
+	module.Options.TerraformBinary = writeScript(t, "exec sleep 30\n")
+	module.RetryPolicy = &RetryPolicy{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := module.Apply(ctx, &testing.T{})
+	var moduleErr *ModuleError
+	if !errors.As(err, &moduleErr) || moduleErr.Operation != "terraform apply timeout" {
+		t.Fatalf("expected apply timeout error, got %v", err)
+	}
+	if !module.ApplyFailed {
+		t.Error("expected module to be marked as ApplyFailed")
+	}
+}
@@ -187,6 +187,97 @@ func TestWithOptions(t *testing.T) {
 			t.Errorf("WithExamplesPath did not set ExamplesPath correctly")
 		}
 	})
+
+	t.Run("WithIdempotency", func(t *testing.T) {
+		c := &Config{}
+		WithIdempotency(true)(c)
+		if !c.Idempotency {
+			t.Errorf("WithIdempotency(true) did not set Idempotency to true")
+		}
+	})
+
+	t.Run("WithUpgrade", func(t *testing.T) {
+		c := &Config{}
+		WithUpgrade(true)(c)
+		WithUpgradeApply(true)(c)
+		if !c.Upgrade || !c.UpgradeApply {
+			t.Errorf("WithUpgrade/WithUpgradeApply did not set Upgrade and UpgradeApply to true")
+		}
+	})
+
+	t.Run("WithPlanOnly", func(t *testing.T) {
+		c := &Config{}
+		WithPlanOnly(true)(c)
+		if !c.PlanOnly {
+			t.Errorf("WithPlanOnly(true) did not set PlanOnly to true")
+		}
+	})
+
+	t.Run("WithValidate", func(t *testing.T) {
+		c := &Config{}
+		WithValidate(true)(c)
+		if !c.Validate {
+			t.Errorf("WithValidate(true) did not set Validate to true")
+		}
+	})
+
+	t.Run("WithFixturePath", func(t *testing.T) {
+		c := &Config{}
+		WithFixturePath("/test/fixture")(c)
+		if c.FixturePath != "/test/fixture" {
+			t.Errorf("WithFixturePath did not set FixturePath correctly")
+		}
+	})
+
+	t.Run("WithJournalPath", func(t *testing.T) {
+		c := &Config{}
+		WithJournalPath("/test/journal.jsonl")(c)
+		if c.JournalPath != "/test/journal.jsonl" {
+			t.Errorf("WithJournalPath did not set JournalPath correctly")
+		}
+	})
+
+	t.Run("WithTerraformBinary", func(t *testing.T) {
+		c := &Config{}
+		WithTerraformBinary("tofu")(c)
+		if c.TerraformBinary != "tofu" {
+			t.Errorf("WithTerraformBinary did not set TerraformBinary correctly")
+		}
+	})
+
+	t.Run("WithTags", func(t *testing.T) {
+		c := &Config{}
+		WithTags("smoke && !slow")(c)
+		WithExcludeTags("expensive")(c)
+		if c.Tags != "smoke && !slow" || c.ExcludeTags != "expensive" {
+			t.Errorf("WithTags/WithExcludeTags did not set Tags and ExcludeTags correctly")
+		}
+	})
+
+	t.Run("WithIsolate", func(t *testing.T) {
+		c := &Config{}
+		WithIsolate(true)(c)
+		if !c.Isolate {
+			t.Errorf("WithIsolate(true) did not set Isolate to true")
+		}
+	})
+
+	t.Run("WithChangedSince", func(t *testing.T) {
+		c := &Config{}
+		WithChangedSince("origin/main")(c)
+		if c.ChangedSince != "origin/main" {
+			t.Errorf("WithChangedSince() did not set ChangedSince correctly")
+		}
+	})
+
+	t.Run("WithTerraformVersions", func(t *testing.T) {
+		c := &Config{}
+		WithTerraformVersions("1.9.8", "1.10.0")(c)
+		WithTerraformBinariesDir("/test/bin")(c)
+		if !reflect.DeepEqual(c.TerraformVersions, []string{"1.9.8", "1.10.0"}) || c.TerraformBinariesDir != "/test/bin" {
+			t.Errorf("WithTerraformVersions/WithTerraformBinariesDir did not set the version matrix correctly")
+		}
+	})
 }
 
 func TestGetExamplesPath(t *testing.T) {
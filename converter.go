@@ -44,6 +44,8 @@ func (c *DefaultSourceConverter) ConvertToLocal(ctx context.Context, modulePath
 		return nil, fmt.Errorf("failed to compile submodule regex: %w", err)
 	}
 
+	localRoot := localModuleRoot(modulePath)
+
 	for _, file := range files {
 		select {
 		case <-ctx.Done():
@@ -62,7 +64,7 @@ func (c *DefaultSourceConverter) ConvertToLocal(ctx context.Context, modulePath
 			return filesToRestore, fmt.Errorf("failed to parse %s: %s", file, diags.Error())
 		}
 
-		if !c.updateModuleBlocks(parsedFile.Body(), moduleSource, submoduleRegex) {
+		if !c.updateModuleBlocks(parsedFile.Body(), moduleSource, submoduleRegex, localRoot) {
 			continue
 		}
 
@@ -114,20 +116,20 @@ func (c *DefaultSourceConverter) updateVersionInContent(content, latestVersion s
 	return content
 }
 
-func (c *DefaultSourceConverter) updateModuleBlocks(body *hclwrite.Body, moduleSource string, submoduleRegex *regexp.Regexp) bool {
+func (c *DefaultSourceConverter) updateModuleBlocks(body *hclwrite.Body, moduleSource string, submoduleRegex *regexp.Regexp, localRoot string) bool {
 	changed := false
 	for _, block := range body.Blocks() {
-		if block.Type() == "module" && c.updateModuleBlock(block, moduleSource, submoduleRegex) {
+		if block.Type() == "module" && c.updateModuleBlock(block, moduleSource, submoduleRegex, localRoot) {
 			changed = true
 		}
-		if c.updateModuleBlocks(block.Body(), moduleSource, submoduleRegex) {
+		if c.updateModuleBlocks(block.Body(), moduleSource, submoduleRegex, localRoot) {
 			changed = true
 		}
 	}
 	return changed
 }
 
-func (c *DefaultSourceConverter) updateModuleBlock(block *hclwrite.Block, moduleSource string, submoduleRegex *regexp.Regexp) bool {
+func (c *DefaultSourceConverter) updateModuleBlock(block *hclwrite.Block, moduleSource string, submoduleRegex *regexp.Regexp, localRoot string) bool {
 	attr := block.Body().GetAttribute("source")
 	if attr == nil {
 		return false
@@ -140,12 +142,12 @@ func (c *DefaultSourceConverter) updateModuleBlock(block *hclwrite.Block, module
 
 	switch {
 	case sourceValue == moduleSource:
-		block.Body().SetAttributeValue("source", cty.StringVal("../../"))
+		block.Body().SetAttributeValue("source", cty.StringVal(localRoot))
 		block.Body().RemoveAttribute("version")
 		return true
 	case submoduleRegex != nil:
 		if matches := submoduleRegex.FindStringSubmatch(sourceValue); len(matches) == 2 {
-			localPath := fmt.Sprintf("../../modules/%s", strings.TrimPrefix(matches[1], "/"))
+			localPath := localRoot + "modules/" + strings.TrimPrefix(matches[1], "/")
 			block.Body().SetAttributeValue("source", cty.StringVal(localPath))
 			block.Body().RemoveAttribute("version")
 			return true
@@ -155,6 +157,24 @@ func (c *DefaultSourceConverter) updateModuleBlock(block *hclwrite.Block, module
 	return false
 }
 
+// defaultLocalModuleRoot is the module root as seen from examples/<name>.
+const defaultLocalModuleRoot = "../../"
+
+// localModuleRoot returns the relative path from an example to the module
+// root. Nested examples such as examples/network/peering sit deeper than the
+// default ../../.
+func localModuleRoot(modulePath string) string {
+	absPath, err := filepath.Abs(modulePath)
+	if err != nil {
+		return defaultLocalModuleRoot
+	}
+	rel, err := filepath.Rel(absPath, moduleRootDir(filepath.Dir(absPath)))
+	if err != nil {
+		return defaultLocalModuleRoot
+	}
+	return filepath.ToSlash(rel) + "/"
+}
+
 func attributeStringValue(attr *hclwrite.Attribute) (string, bool) {
 	tokens := attr.Expr().BuildTokens(nil)
 	if len(tokens) == 0 {
@@ -282,7 +282,7 @@ func TestDefaultSourceConverter_updateModuleBlock(t *testing.T) {
 			block := rootBody.AppendNewBlock("module", []string{"test"})
 			block.Body().SetAttributeValue("source", cty.StringVal(tt.sourceValue))
 
-			changed := converter.updateModuleBlock(block, moduleSource, submoduleRegex)
+			changed := converter.updateModuleBlock(block, moduleSource, submoduleRegex, defaultLocalModuleRoot)
 
 			if changed != tt.shouldChange {
 				t.Errorf("updateModuleBlock() changed = %v, want %v", changed, tt.shouldChange)
@@ -343,3 +343,27 @@ func testContext(t *testing.T) context.Context {
 	t.Helper()
 	return context.Background()
 }
+
+func TestLocalModuleRoot(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "main.tf"), "")
+	writeFile(t, filepath.Join(root, "examples", "default", "main.tf"), "")
+	writeFile(t, filepath.Join(root, "examples", "network", "main.tf"), "")
+	writeFile(t, filepath.Join(root, "examples", "network", "peering", "main.tf"), "")
+
+	tests := []struct {
+		example string
+		want    string
+	}{
+		{"default", "../../"},
+		{"network", "../../"},
+		{"network/peering", "../../../"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.example, func(t *testing.T) {
+			if got := localModuleRoot(filepath.Join(root, "examples", filepath.FromSlash(tt.example))); got != tt.want {
+				t.Errorf("localModuleRoot() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
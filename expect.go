@@ -0,0 +1,37 @@
+package validor
+
+import (
+	"fmt"
+	"regexp"
+)
+
+func (m *Module) expectsFailure() bool {
+	return m.Manifest != nil && m.Manifest.ExpectError != ""
+}
+
+// checkExpectedFailure compares the outcome of operation with the expect_error
+// pattern of the manifest. A matching error is a pass; success or any other
+// error fails the example.
+func (m *Module) checkExpectedFailure(t testLogger, operation string, err error) error {
+	t.Helper()
+
+	pattern := m.Manifest.ExpectError
+	re, compileErr := regexp.Compile(pattern)
+	if compileErr != nil {
+		m.ApplyFailed = true
+		return m.recordError(t, "expected failure", fmt.Errorf("invalid expect_error %q: %w", pattern, compileErr))
+	}
+	if err == nil {
+		return m.recordError(t, "expected failure",
+			fmt.Errorf("%s succeeded, expected an error matching %q", operation, pattern))
+	}
+	if !re.MatchString(err.Error()) {
+		m.ApplyFailed = true
+		return m.recordError(t, "expected failure",
+			fmt.Errorf("%s failed with an error not matching %q: %w", operation, pattern, err))
+	}
+
+	m.FailedAsExpected = true
+	t.Logf("✓ Module %s failed as expected: %s error matches %q", m.Name, operation, pattern)
+	return nil
+}
@@ -0,0 +1,137 @@
+package validor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+func TestModule_ApplyExpectedFailure(t *testing.T) {
+	tests := []struct {
+		name        string
+		script      string
+		expectError string
+		wantErr     bool
+		wantApply   bool
+		wantPass    bool
+	}{
+		{
+			name:        "matching error passes",
+			script:      `[ "$1" = apply ] && { echo 'Error: Invalid value for variable "sku"' >&2; exit 1; }; exit 0`,
+			expectError: `Invalid value for variable "sku"`,
+			wantPass:    true,
+		},
+		{
+			name:        "different error fails",
+			script:      `[ "$1" = apply ] && { echo 'Error: quota exceeded' >&2; exit 1; }; exit 0`,
+			expectError: `Invalid value for variable`,
+			wantErr:     true,
+			wantApply:   true,
+		},
+		{
+			name:        "unexpected success fails",
+			script:      `exit 0`,
+			expectError: `Invalid value for variable`,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			module := NewModule("negative", t.TempDir())
+			module.Options.TerraformBinary = writeScript(t, tt.script+"\n")
+			module.RetryPolicy = &RetryPolicy{}
+			module.Manifest = &Manifest{ExpectError: tt.expectError}
+
+			err := module.Apply(t.Context(), t)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if module.ApplyFailed != tt.wantApply {
+				t.Errorf("ApplyFailed = %v, want %v", module.ApplyFailed, tt.wantApply)
+			}
+			if module.FailedAsExpected != tt.wantPass {
+				t.Errorf("FailedAsExpected = %v, want %v", module.FailedAsExpected, tt.wantPass)
+			}
+		})
+	}
+}
+
+func TestModule_PlanExpectedFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		planErr  error
+		wantErr  bool
+		wantPass bool
+	}{
+		{name: "matching error passes", planErr: errors.New(`Error: Invalid value for variable "sku"`), wantPass: true},
+		{name: "different error fails", planErr: errors.New("Error: quota exceeded"), wantErr: true},
+		{name: "unexpected success fails", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			module := NewModule("negative", t.TempDir())
+			module.Manifest = &Manifest{ExpectError: "Invalid value for variable"}
+			module.planHook = func(ctx context.Context, tb *testing.T, m *Module) (*terraform.PlanStruct, error) {
+				if tt.planErr != nil {
+					return nil, tt.planErr
+				}
+				return planWithChanges(nil), nil
+			}
+
+			err := module.Plan(t.Context(), t)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Plan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if module.FailedAsExpected != tt.wantPass {
+				t.Errorf("FailedAsExpected = %v, want %v", module.FailedAsExpected, tt.wantPass)
+			}
+		})
+	}
+}
+
+func TestLoadManifest_InvalidExpectError(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ManifestFileName), "expect_error: \"(unclosed\"\n")
+	if _, err := LoadManifest(dir); err == nil {
+		t.Error("expected an error for an invalid expect_error pattern")
+	}
+}
+
+func TestRunModuleTests_ExpectedFailureSkipsDestroy(t *testing.T) {
+	callLog := filepath.Join(t.TempDir(), "calls")
+	script := writeScript(t, `echo "$1" >> `+callLog+`
+[ "$1" = apply ] && { echo 'Error: Invalid value for variable "sku"' >&2; exit 1; }
+[ "$1" = state ] && { echo 'No state file was found!' >&2; exit 1; }
+exit 0
+`)
+	t.Setenv("PATH", filepath.Dir(script)+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	examplePath := t.TempDir()
+	writeFile(t, filepath.Join(examplePath, ManifestFileName), "expect_error: Invalid value for variable\n")
+	module := NewModule("negative", examplePath)
+	module.RetryPolicy = &RetryPolicy{}
+	if err := module.LoadManifest(); err != nil {
+		t.Fatal(err)
+	}
+
+	passed := t.Run("examples", func(t *testing.T) {
+		runModuleTests(t, []*Module{module}, false, NewConfig(WithRetryPolicy(&RetryPolicy{})), nil, "registry")
+	})
+	if !passed {
+		t.Error("expected the negative example to pass")
+	}
+
+	calls, err := os.ReadFile(callLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "version\ninit\napply\nstate\n"; string(calls) != want {
+		t.Errorf("terraform calls = %q, want %q", calls, want)
+	}
+}
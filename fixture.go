@@ -0,0 +1,135 @@
+package validor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// FixtureDirName is the directory inside the examples path holding shared
+// infrastructure that is applied once before all examples.
+const FixtureDirName = "_fixture"
+
+func getFixturePath(config *Config) string {
+	if config.FixturePath != "" {
+		return config.FixturePath
+	}
+	return filepath.Join(getExamplesPath(config), FixtureDirName)
+}
+
+// setupFixture applies the shared fixture, if there is one, and schedules its
+// destroy once every example has finished. It returns the fixture outputs.
+func (r *moduleRunner) setupFixture(ctx context.Context, t *testing.T, retryPolicy *RetryPolicy) map[string]any {
+	path := getFixturePath(r.config)
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return nil
+	}
+
+	fixture := NewModule(FixtureDirName, path)
+	fixture.Options.TerraformBinary = r.toolchain.Binary
+	fixture.RetryPolicy = retryPolicy
+	if r.config.workspace != nil {
+		r.config.workspace.track(fixture)
+	}
+
+	if !r.config.SkipDestroy {
+		t.Cleanup(func() {
+			t.Logf("Destroying shared fixture %s", path)
+			r.destroy(ctx, t, fixture)
+		})
+	}
+
+	t.Logf("Applying shared fixture %s", path)
+	r.record(t, fixture, JournalApplying)
+	applyCtx, cancel := applyContext(ctx, r.config, t.Deadline)
+	defer cancel()
+	if err := fixture.Apply(applyCtx, t); err != nil {
+		t.Fatal(redError(fmt.Sprintf("Fixture apply failed: %v", err)))
+	}
+	r.record(t, fixture, JournalApplied)
+
+	outputs, err := readOutputs(applyCtx, t, fixture)
+	if err != nil {
+		t.Fatal(redError(fmt.Sprintf("Failed to read fixture outputs: %v", err)))
+	}
+	return outputs
+}
+
+func readOutputs(ctx context.Context, t testLogger, module *Module) (map[string]any, error) {
+	stdout, err := runTerraform(ctx, t, module.Options, "output", "-json", "-no-color")
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]struct {
+		Value any `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse output of module %s: %w", module.Name, err)
+	}
+
+	outputs := make(map[string]any, len(raw))
+	for name, output := range raw {
+		outputs[name] = output.Value
+	}
+	return outputs, nil
+}
+
+// passFixtureOutputs sets the fixture outputs as variables on the module.
+// Only variables the example declares are set, since terraform rejects -var
+// for undeclared variables, and values the example already sets are kept.
+func passFixtureOutputs(module *Module, outputs map[string]any) error {
+	if len(outputs) == 0 {
+		return nil
+	}
+
+	declared, err := declaredVariables(module.Path)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range declared {
+		value, ok := outputs[name]
+		if !ok {
+			continue
+		}
+		if module.Options.Vars == nil {
+			module.Options.Vars = make(map[string]any)
+		}
+		if _, set := module.Options.Vars[name]; !set {
+			module.Options.Vars[name] = value
+		}
+	}
+	return nil
+}
+
+func declaredVariables(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		parsed, diags := hclsyntax.ParseConfig(content, file, hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, diags)
+		}
+		for _, block := range parsed.Body.(*hclsyntax.Body).Blocks {
+			if block.Type == "variable" && len(block.Labels) == 1 {
+				names = append(names, block.Labels[0])
+			}
+		}
+	}
+	return names, nil
+}
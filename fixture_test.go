@@ -0,0 +1,189 @@
+package validor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPassFixtureOutputs(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "variables.tf"), `
+variable "resource_group" {}
+variable "location" {
+  type = string
+}
+`)
+	writeFile(t, filepath.Join(dir, "main.tf"), `locals { name = "x" }`)
+
+	module := NewModule("example", dir)
+	module.Options.Vars = map[string]any{"location": "westeurope"}
+
+	outputs := map[string]any{
+		"resource_group": "rg-shared",
+		"location":       "northeurope",
+		"undeclared":     "ignored",
+	}
+	if err := passFixtureOutputs(module, outputs); err != nil {
+		t.Fatalf("passFixtureOutputs() error = %v", err)
+	}
+
+	want := map[string]any{"resource_group": "rg-shared", "location": "westeurope"}
+	if !reflect.DeepEqual(module.Options.Vars, want) {
+		t.Errorf("Vars = %v, want %v", module.Options.Vars, want)
+	}
+}
+
+func TestPassFixtureOutputs_InvalidHCL(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "main.tf"), `variable "x" {`)
+
+	if err := passFixtureOutputs(NewModule("example", dir), map[string]any{"x": 1}); err == nil {
+		t.Fatal("expected an error for invalid HCL")
+	}
+}
+
+func TestRunModuleTests_Fixture(t *testing.T) {
+	examplesPath := t.TempDir()
+	fixturePath := filepath.Join(examplesPath, FixtureDirName)
+	callLog := filepath.Join(t.TempDir(), "calls")
+	writeFile(t, filepath.Join(fixturePath, "main.tf"), "")
+
+	script := writeScript(t, `echo "$1" >> `+callLog+`
+case "$1" in
+  output) echo '{"resource_group":{"value":"rg-shared","type":"string","sensitive":false}}' ;;
+esac
+exit 0
+`)
+	t.Setenv("PATH", filepath.Dir(script)+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	examplePath := filepath.Join(examplesPath, "example")
+	writeFile(t, filepath.Join(examplePath, "variables.tf"), `variable "resource_group" {}`)
+
+	module := NewModule("example", examplePath)
+	var got any
+	module.applyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+		got = m.Options.Vars["resource_group"]
+		return nil
+	}
+	module.destroyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+		return nil
+	}
+
+	config := NewConfig(WithExamplesPath(examplesPath))
+	t.Run("examples", func(t *testing.T) {
+		runModuleTests(t, []*Module{module}, true, config, nil, "registry")
+	})
+
+	if got != "rg-shared" {
+		t.Errorf("example received resource_group = %v, want rg-shared", got)
+	}
+
+	calls, err := os.ReadFile(callLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "version\ninit\napply\noutput\ndestroy\nstate\n"; string(calls) != want {
+		t.Errorf("fixture terraform calls = %q, want %q", calls, want)
+	}
+}
+
+func TestRunModuleTests_FixtureOutputsYieldToManifestVars(t *testing.T) {
+	examplesPath := t.TempDir()
+	writeFile(t, filepath.Join(examplesPath, FixtureDirName, "main.tf"), "")
+
+	script := writeScript(t, `[ "$1" = output ] && echo '{"resource_group":{"value":"rg-shared","type":"string","sensitive":false}}'
+exit 0
+`)
+	t.Setenv("PATH", filepath.Dir(script)+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	examplePath := filepath.Join(examplesPath, "example")
+	writeFile(t, filepath.Join(examplePath, "variables.tf"), `variable "resource_group" {}`)
+	writeFile(t, filepath.Join(examplePath, ManifestFileName), "vars:\n  resource_group: rg-manifest\n")
+
+	module := NewModule("example", examplePath)
+	var got any
+	module.applyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+		got = m.Options.Vars["resource_group"]
+		return nil
+	}
+	module.destroyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+		return nil
+	}
+
+	t.Run("examples", func(t *testing.T) {
+		runModuleTests(t, []*Module{module}, false, NewConfig(WithExamplesPath(examplesPath)), nil, "registry")
+	})
+
+	if got != "rg-manifest" {
+		t.Errorf("example received resource_group = %v, want rg-manifest from the manifest", got)
+	}
+}
+
+func TestRunModuleTests_FixtureSkipped(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+	}{
+		{"plan only", NewConfig(WithPlanOnly(true))},
+		{"nothing selected", &Config{ExceptionList: []string{"example"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			examplesPath := t.TempDir()
+			writeFile(t, filepath.Join(examplesPath, FixtureDirName, "main.tf"), "")
+			examplePath := filepath.Join(examplesPath, "example")
+			writeFile(t, filepath.Join(examplePath, "main.tf"), "")
+
+			callLog := filepath.Join(t.TempDir(), "calls")
+			script := writeScript(t, `echo "$1 $(pwd)" >> `+callLog+`
+[ "$1" = show ] && echo '{"format_version":"1.2"}'
+exit 0
+`)
+			t.Setenv("PATH", filepath.Dir(script)+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+			module := NewModule("example", examplePath)
+			module.RetryPolicy = &RetryPolicy{}
+			tt.config.ExamplesPath = examplesPath
+			t.Run("examples", func(t *testing.T) {
+				runModuleTests(t, []*Module{module}, false, tt.config, nil, "registry")
+			})
+
+			calls, err := os.ReadFile(callLog)
+			if err != nil && !os.IsNotExist(err) {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(calls), FixtureDirName) {
+				t.Errorf("expected the fixture to be skipped, terraform calls:\n%s", calls)
+			}
+		})
+	}
+}
+
+func TestDiscoverModules_SkipsFixture(t *testing.T) {
+	examplesPath := t.TempDir()
+	writeFile(t, filepath.Join(examplesPath, FixtureDirName, "main.tf"), "")
+	writeFile(t, filepath.Join(examplesPath, "default", "main.tf"), "")
+
+	modules, err := NewModuleManager(examplesPath).DiscoverModules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := moduleNames(modules); strings.Contains(names, FixtureDirName) || names != "default" {
+		t.Errorf("discovered %s, want only default", names)
+	}
+}
@@ -0,0 +1,177 @@
+package validor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// dependencyGraph orders examples by the depends_on entries in their
+// manifests and lets an example wait until its dependencies are applied.
+type dependencyGraph struct {
+	order      []*Module
+	dependents map[string][]string
+	applied    map[string]chan struct{}
+
+	mu       sync.Mutex
+	outcomes map[string]string
+	started  map[string]bool
+}
+
+func newDependencyGraph(modules []*Module) (*dependencyGraph, error) {
+	g := &dependencyGraph{
+		dependents: make(map[string][]string),
+		applied:    make(map[string]chan struct{}),
+		outcomes:   make(map[string]string),
+		started:    make(map[string]bool),
+	}
+
+	byName := make(map[string]*Module, len(modules))
+	for _, module := range modules {
+		byName[module.Name] = module
+		g.applied[module.Name] = make(chan struct{})
+	}
+	for _, module := range modules {
+		for _, dependency := range module.dependsOn() {
+			if dependency == module.Name {
+				return nil, fmt.Errorf("example %s depends on itself", module.Name)
+			}
+			if _, ok := byName[dependency]; ok {
+				g.dependents[dependency] = append(g.dependents[dependency], module.Name)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(modules))
+	var visit func(module *Module, path []string) error
+	visit = func(module *Module, path []string) error {
+		switch state[module.Name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle between examples: %s", strings.Join(append(path, module.Name), " -> "))
+		}
+		state[module.Name] = visiting
+		for _, dependency := range module.dependsOn() {
+			if dep, ok := byName[dependency]; ok {
+				if err := visit(dep, append(path, module.Name)); err != nil {
+					return err
+				}
+			}
+		}
+		state[module.Name] = visited
+		g.order = append(g.order, module)
+		return nil
+	}
+	for _, module := range modules {
+		if err := visit(module, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return g, nil
+}
+
+// waitForDependencies blocks until every dependency of module has finished
+// its apply phase. It returns a non-empty reason when module cannot run
+// because a dependency failed, was blocked itself or is not part of the run.
+func (g *dependencyGraph) waitForDependencies(ctx context.Context, module *Module) (string, error) {
+	for _, dependency := range module.dependsOn() {
+		done, ok := g.applied[dependency]
+		if !ok {
+			return fmt.Sprintf("dependency %s is not part of this run", dependency), nil
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return "", fmt.Errorf("waiting for dependency %s: %w", dependency, ctx.Err())
+		}
+
+		g.mu.Lock()
+		outcome := g.outcomes[dependency]
+		g.mu.Unlock()
+		if outcome != "" {
+			return fmt.Sprintf("dependency %s %s", dependency, outcome), nil
+		}
+	}
+	return "", nil
+}
+
+// markApplied records that name finished its apply phase. An empty outcome
+// means it succeeded, anything else explains why dependents are blocked.
+func (g *dependencyGraph) markApplied(name, outcome string) {
+	g.mu.Lock()
+	g.outcomes[name] = outcome
+	g.mu.Unlock()
+	close(g.applied[name])
+}
+
+// markStarted records that the subtest of name began.
+func (g *dependencyGraph) markStarted(name string) {
+	g.mu.Lock()
+	g.started[name] = true
+	g.mu.Unlock()
+}
+
+// markNotStarted finishes the apply phase of every module whose subtest never
+// began, for example because -run filtered it out, so its dependents are
+// blocked instead of waiting forever.
+func (g *dependencyGraph) markNotStarted(modules []*Module) {
+	g.mu.Lock()
+	var names []string
+	for _, module := range modules {
+		if !g.started[module.Name] {
+			names = append(names, module.Name)
+		}
+	}
+	g.mu.Unlock()
+
+	for _, name := range names {
+		g.markApplied(name, "is not part of this run")
+	}
+}
+
+// levels groups the examples by dependency depth. An example is one level
+// above the deepest example it depends on, so every level only depends on
+// the levels before it.
+func (g *dependencyGraph) levels() [][]*Module {
+	depth := make(map[string]int, len(g.order))
+	var levels [][]*Module
+	for _, module := range g.order {
+		level := 0
+		for _, dependency := range module.dependsOn() {
+			if d, ok := depth[dependency]; ok && d+1 > level {
+				level = d + 1
+			}
+		}
+		depth[module.Name] = level
+		if level == len(levels) {
+			levels = append(levels, nil)
+		}
+		levels[level] = append(levels[level], module)
+	}
+	return levels
+}
+
+func (g *dependencyGraph) hasDependents(name string) bool {
+	return len(g.dependents[name]) > 0
+}
+
+// destroyOrder returns modules in reverse dependency order, so that an
+// example is destroyed only after everything that depends on it.
+func (g *dependencyGraph) destroyOrder(modules []*Module) []*Module {
+	var ordered []*Module
+	for _, module := range slices.Backward(g.order) {
+		if slices.Contains(modules, module) {
+			ordered = append(ordered, module)
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,170 @@
+package validor
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func moduleWithDeps(name string, dependsOn ...string) *Module {
+	module := NewModule(name, "")
+	module.Manifest = &Manifest{DependsOn: dependsOn}
+	return module
+}
+
+func moduleNames(modules []*Module) string {
+	names := make([]string, len(modules))
+	for i, module := range modules {
+		names[i] = module.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func TestNewDependencyGraph(t *testing.T) {
+	tests := []struct {
+		name    string
+		modules []*Module
+		order   string
+		wantErr string
+	}{
+		{
+			name:    "no dependencies keeps order",
+			modules: []*Module{moduleWithDeps("b"), moduleWithDeps("a")},
+			order:   "b,a",
+		},
+		{
+			name:    "dependencies first",
+			modules: []*Module{moduleWithDeps("app", "vnet", "kv"), moduleWithDeps("kv", "vnet"), moduleWithDeps("vnet")},
+			order:   "vnet,kv,app",
+		},
+		{
+			name:    "missing dependency is ignored",
+			modules: []*Module{moduleWithDeps("app", "vnet")},
+			order:   "app",
+		},
+		{
+			name:    "cycle",
+			modules: []*Module{moduleWithDeps("a", "b"), moduleWithDeps("b", "a")},
+			wantErr: "a -> b -> a",
+		},
+		{
+			name:    "self dependency",
+			modules: []*Module{moduleWithDeps("a", "a")},
+			wantErr: "depends on itself",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph, err := newDependencyGraph(tt.modules)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := moduleNames(graph.order); got != tt.order {
+				t.Errorf("order = %s, want %s", got, tt.order)
+			}
+			if got := moduleNames(graph.destroyOrder(tt.modules)); got != reverseNames(tt.order) {
+				t.Errorf("destroy order = %s, want %s", got, reverseNames(tt.order))
+			}
+		})
+	}
+}
+
+func reverseNames(names string) string {
+	parts := strings.Split(names, ",")
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestDependencyGraph_WaitForDependencies(t *testing.T) {
+	vnet := moduleWithDeps("vnet")
+	kv := moduleWithDeps("kv")
+	app := moduleWithDeps("app", "vnet", "kv")
+	orphan := moduleWithDeps("orphan", "missing")
+
+	graph, err := newDependencyGraph([]*Module{vnet, kv, app, orphan})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	graph.markApplied("vnet", "")
+	graph.markApplied("kv", "failed")
+
+	reason, err := graph.waitForDependencies(context.Background(), app)
+	if err != nil || reason != "dependency kv failed" {
+		t.Errorf("app: reason = %q, err = %v", reason, err)
+	}
+
+	reason, err = graph.waitForDependencies(context.Background(), orphan)
+	if err != nil || !strings.Contains(reason, "not part of this run") {
+		t.Errorf("orphan: reason = %q, err = %v", reason, err)
+	}
+
+	reason, err = graph.waitForDependencies(context.Background(), vnet)
+	if err != nil || reason != "" {
+		t.Errorf("vnet: reason = %q, err = %v", reason, err)
+	}
+}
+
+func TestDependencyGraph_WaitRespectsContext(t *testing.T) {
+	app := moduleWithDeps("app", "vnet")
+	graph, err := newDependencyGraph([]*Module{moduleWithDeps("vnet"), app})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := graph.waitForDependencies(ctx, app); err == nil {
+		t.Fatal("expected an error for a cancelled context")
+	}
+}
+
+func TestDependencyGraph_MarkNotStarted(t *testing.T) {
+	vnet := moduleWithDeps("vnet")
+	app := moduleWithDeps("app", "vnet")
+	graph, err := newDependencyGraph([]*Module{vnet, app})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	graph.markStarted("app")
+	graph.markNotStarted([]*Module{vnet, app})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	reason, err := graph.waitForDependencies(ctx, app)
+	if err != nil || reason != "dependency vnet is not part of this run" {
+		t.Errorf("app: reason = %q, err = %v", reason, err)
+	}
+}
+
+func TestDependencyGraph_Levels(t *testing.T) {
+	graph, err := newDependencyGraph([]*Module{
+		moduleWithDeps("app", "vnet", "kv"),
+		moduleWithDeps("kv", "vnet"),
+		moduleWithDeps("vnet"),
+		moduleWithDeps("orphan", "missing"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, level := range graph.levels() {
+		got = append(got, moduleNames(level))
+	}
+	if want := []string{"vnet,orphan", "kv", "app"}; !slices.Equal(got, want) {
+		t.Errorf("levels() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package validor
+
+import (
+	"context"
+	"testing"
+)
+
+// HookFunc runs custom validation as part of the apply and destroy cycle of
+// an example. A returned error fails the example.
+type HookFunc func(ctx context.Context, t *testing.T, m *Module) error
+
+type hookPhase string
+
+const (
+	hookPreApply   hookPhase = "pre-apply"
+	hookPostApply  hookPhase = "post-apply"
+	hookPreDestroy hookPhase = "pre-destroy"
+)
+
+type lifecycleHook struct {
+	phase    hookPhase
+	examples []string
+	run      HookFunc
+}
+
+// WithPreApply runs hook before apply. Without examples it runs for every
+// example, otherwise only for examples matching one of the names or patterns.
+// An error skips the apply.
+func WithPreApply(hook HookFunc, examples ...string) Option {
+	return withHook(hookPreApply, hook, examples)
+}
+
+// WithPostApply runs hook after a successful apply and the output assertions.
+func WithPostApply(hook HookFunc, examples ...string) Option {
+	return withHook(hookPostApply, hook, examples)
+}
+
+// WithPreDestroy runs hook before destroy. The example is destroyed even when
+// the hook fails.
+func WithPreDestroy(hook HookFunc, examples ...string) Option {
+	return withHook(hookPreDestroy, hook, examples)
+}
+
+func withHook(phase hookPhase, hook HookFunc, examples []string) Option {
+	return func(c *Config) {
+		c.hooks = append(c.hooks, lifecycleHook{phase: phase, examples: examples, run: hook})
+	}
+}
+
+// exampleName is the example directory name, also for version matrix variants.
+func (m *Module) exampleName() string {
+	if m.example != "" {
+		return m.example
+	}
+	return m.Name
+}
+
+// runHooks runs the hooks of phase that apply to module and returns the last
+// error, after recording every error on the module.
+func runHooks(ctx context.Context, t *testing.T, config *Config, module *Module, phase hookPhase) error {
+	var failed error
+	for _, hook := range config.hooks {
+		if hook.phase != phase || (len(hook.examples) > 0 && !matchesAnyPattern(hook.examples, module.exampleName())) {
+			continue
+		}
+		t.Logf("Running %s hook for module %s", phase, module.Name)
+		if err := hook.run(ctx, t, module); err != nil {
+			failed = module.recordError(t, string(phase)+" hook", err)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,76 @@
+package validor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunModuleTests_Hooks(t *testing.T) {
+	var calls []string
+	record := func(call string) HookFunc {
+		return func(ctx context.Context, t *testing.T, m *Module) error {
+			calls = append(calls, call+":"+m.Name)
+			return nil
+		}
+	}
+
+	module := NewModule("network", t.TempDir())
+	module.applyHook = func(ctx context.Context, t *testing.T, m *Module) error {
+		calls = append(calls, "apply:"+m.Name)
+		return nil
+	}
+	module.destroyHook = func(ctx context.Context, t *testing.T, m *Module) error {
+		calls = append(calls, "destroy:"+m.Name)
+		return nil
+	}
+	module.cleanupHook = func(ctx context.Context, t *testing.T, m *Module) error { return nil }
+
+	config := NewConfig(
+		WithPreApply(record("pre-apply")),
+		WithPostApply(record("post-apply"), "net*"),
+		WithPostApply(record("other"), "storage"),
+		WithPreDestroy(record("pre-destroy")),
+	)
+	t.Run("examples", func(t *testing.T) {
+		runModuleTests(t, []*Module{module}, false, config, nil, "registry")
+	})
+
+	want := "pre-apply:network,apply:network,post-apply:network,pre-destroy:network,destroy:network"
+	if got := strings.Join(calls, ","); got != want {
+		t.Errorf("calls = %s, want %s", got, want)
+	}
+}
+
+func TestRunHooks(t *testing.T) {
+	failing := func(ctx context.Context, t *testing.T, m *Module) error {
+		return errors.New("private endpoint does not resolve")
+	}
+	var ran int
+	counting := func(ctx context.Context, t *testing.T, m *Module) error {
+		ran++
+		return nil
+	}
+
+	config := NewConfig(
+		WithPostApply(failing, "re:private-.*"),
+		WithPostApply(counting),
+		WithPreDestroy(counting),
+	)
+
+	variant := &Module{Name: "private-endpoint/1.9.8", example: "private-endpoint"}
+	err := runHooks(t.Context(), t, config, variant, hookPostApply)
+	var moduleErr *ModuleError
+	if !errors.As(err, &moduleErr) || moduleErr.Operation != "post-apply hook" {
+		t.Fatalf("expected a post-apply hook error, got %v", err)
+	}
+	if ran != 1 || len(variant.Errors) != 1 {
+		t.Errorf("ran = %d, errors = %v, want every post-apply hook to run once", ran, variant.Errors)
+	}
+
+	other := NewModule("default", t.TempDir())
+	if err := runHooks(t.Context(), t, config, other, hookPostApply); err != nil {
+		t.Errorf("runHooks() error = %v, the failing hook does not apply to default", err)
+	}
+}
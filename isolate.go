@@ -0,0 +1,202 @@
+package validor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// workspace is a temporary copy of the module root and its examples that an
+// isolated run works in, so the repository itself is never modified.
+type workspace struct {
+	dir     string
+	copies  map[string]string
+	modules []*Module
+}
+
+func newWorkspace(config *Config) (*workspace, error) {
+	examplesPath, err := filepath.Abs(getExamplesPath(config))
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "validor-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create isolated workspace: %w", err)
+	}
+	w := &workspace{dir: dir, copies: make(map[string]string)}
+
+	sources := []string{moduleRootDir(examplesPath), examplesPath}
+	if config.FixturePath != "" {
+		fixturePath, err := filepath.Abs(config.FixturePath)
+		if err != nil {
+			w.remove()
+			return nil, err
+		}
+		sources = append(sources, fixturePath)
+	}
+	for _, source := range sources {
+		if _, copied := w.mapPath(source); copied {
+			continue
+		}
+		if err := w.copy(source); err != nil {
+			w.remove()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+func (w *workspace) copy(source string) error {
+	target := filepath.Join(w.dir, filepath.Base(source))
+	for i := 2; ; i++ {
+		if _, err := os.Stat(target); os.IsNotExist(err) {
+			break
+		}
+		target = filepath.Join(w.dir, fmt.Sprintf("%s-%d", filepath.Base(source), i))
+	}
+	w.copies[source] = target
+	return copyTree(source, target, w.dir)
+}
+
+// mapPath returns where path is in the workspace, if it was copied.
+func (w *workspace) mapPath(path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path, false
+	}
+	for source, target := range w.copies {
+		if isWithin(source, abs) {
+			rel, _ := filepath.Rel(source, abs)
+			return filepath.Join(target, rel), true
+		}
+	}
+	return path, false
+}
+
+func (w *workspace) remove() {
+	os.RemoveAll(w.dir)
+}
+
+// track adds a module whose teardown decides whether the workspace is kept.
+func (w *workspace) track(modules ...*Module) {
+	w.modules = append(w.modules, modules...)
+}
+
+// needsInspection reports whether a tracked module left resources behind or
+// failed to destroy, verify or clean up, so its state has to be inspected.
+func (w *workspace) needsInspection() bool {
+	for _, module := range w.modules {
+		if len(module.RemainingResources) > 0 || hasTeardownError(module) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTeardownError(module *Module) bool {
+	for _, err := range module.Errors {
+		var moduleErr *ModuleError
+		if !errors.As(err, &moduleErr) {
+			continue
+		}
+		operation := moduleErr.Operation
+		if strings.HasPrefix(operation, "terraform destroy") || operation == "destroy verification" || operation == "cleanup" {
+			return true
+		}
+	}
+	return false
+}
+
+// copyTree copies source to target, without hidden directories such as .git
+// and .terraform, without state files and without the skip directory.
+func copyTree(source, target, skip string) error {
+	return filepath.WalkDir(source, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == skip {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		destination := filepath.Join(target, rel)
+
+		switch {
+		case entry.IsDir():
+			if path != source && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(destination, 0o755)
+		case strings.Contains(entry.Name(), ".tfstate"):
+			return nil
+		case entry.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, destination)
+		case entry.Type().IsRegular():
+			return copyFile(path, destination)
+		default:
+			return nil
+		}
+	})
+}
+
+func copyFile(source, target string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(target, content, info.Mode().Perm())
+}
+
+// isolate copies the module root and examples into a workspace and points
+// the modules at their copies. It returns the config for the copy.
+func isolate(t *testing.T, config *Config, modules []*Module) *Config {
+	t.Helper()
+
+	w, err := newWorkspace(config)
+	if err != nil {
+		t.Fatal(redError(fmt.Sprintf("Failed to create isolated workspace: %v", err)))
+	}
+	t.Logf("Running in isolated workspace %s", w.dir)
+	w.track(modules...)
+	t.Cleanup(func() {
+		if config.SkipDestroy || w.needsInspection() {
+			t.Logf("Keeping isolated workspace %s for inspection", w.dir)
+			return
+		}
+		w.remove()
+	})
+
+	for _, module := range modules {
+		path, ok := w.mapPath(module.Path)
+		if !ok {
+			t.Fatal(redError(fmt.Sprintf("Module %s at %s is outside the isolated workspace", module.Name, module.Path)))
+		}
+		if module.Options.TerraformDir == module.Path {
+			module.Options.TerraformDir = path
+		}
+		module.Path = path
+	}
+
+	isolated := *config
+	isolated.workspace = w
+	isolated.ExamplesPath, _ = w.mapPath(getExamplesPath(config))
+	if config.FixturePath != "" {
+		isolated.FixturePath, _ = w.mapPath(config.FixturePath)
+	}
+	return &isolated
+}
@@ -0,0 +1,122 @@
+package validor
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewWorkspace(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "main.tf"), "")
+	writeFile(t, filepath.Join(root, "modules", "subnet", "main.tf"), "")
+	writeFile(t, filepath.Join(root, ".git", "config"), "")
+	writeFile(t, filepath.Join(root, "examples", "default", "main.tf"), "")
+	writeFile(t, filepath.Join(root, "examples", "default", "terraform.tfstate"), "{}")
+	writeFile(t, filepath.Join(root, "examples", "default", ".terraform", "modules.json"), "")
+	fixturePath := filepath.Join(t.TempDir(), "shared")
+	writeFile(t, filepath.Join(fixturePath, "main.tf"), "")
+
+	config := NewConfig(WithExamplesPath(filepath.Join(root, "examples")), WithFixturePath(fixturePath))
+	w, err := newWorkspace(config)
+	if err != nil {
+		t.Fatalf("newWorkspace() error = %v", err)
+	}
+	defer w.remove()
+
+	example, ok := w.mapPath(filepath.Join(root, "examples", "default"))
+	if !ok || !strings.HasPrefix(example, w.dir) {
+		t.Fatalf("mapPath() = %s, %v, want a path in %s", example, ok, w.dir)
+	}
+	for _, path := range []string{"main.tf", "../../main.tf", "../../modules/subnet/main.tf"} {
+		if _, err := os.Stat(filepath.Join(example, path)); err != nil {
+			t.Errorf("expected %s in the workspace: %v", path, err)
+		}
+	}
+	for _, path := range []string{"terraform.tfstate", ".terraform", "../../.git"} {
+		if _, err := os.Stat(filepath.Join(example, path)); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected %s to be left out of the workspace, got %v", path, err)
+		}
+	}
+
+	fixture, ok := w.mapPath(fixturePath)
+	if !ok {
+		t.Fatal("expected the fixture outside the module root to be copied")
+	}
+	if _, err := os.Stat(filepath.Join(fixture, "main.tf")); err != nil {
+		t.Errorf("expected the fixture in the workspace: %v", err)
+	}
+}
+
+func TestRunModuleTests_Isolate(t *testing.T) {
+	callLog := filepath.Join(t.TempDir(), "calls")
+	script := writeScript(t, `echo "$1 $(pwd)" >> `+callLog+`
+[ "$1" = init ] && touch .terraform.lock.hcl
+exit 0
+`)
+	t.Setenv("PATH", filepath.Dir(script)+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "main.tf"), "")
+	examplesPath := filepath.Join(root, "examples")
+	examplePath := filepath.Join(examplesPath, "default")
+	writeFile(t, filepath.Join(examplePath, "main.tf"), "")
+
+	module := NewModule("default", examplePath)
+	module.RetryPolicy = &RetryPolicy{}
+	config := NewConfig(WithExamplesPath(examplesPath), WithIsolate(true))
+
+	t.Run("examples", func(t *testing.T) {
+		runModuleTests(t, []*Module{module}, false, config, nil, "registry")
+	})
+
+	if module.Path == examplePath || module.Options.TerraformDir != module.Path {
+		t.Errorf("module was not moved to the workspace: path %s, terraform dir %s", module.Path, module.Options.TerraformDir)
+	}
+	if _, err := os.Stat(module.Path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the workspace to be removed after the run, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(examplePath, ".terraform.lock.hcl")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the repository to stay untouched, got %v", err)
+	}
+
+	calls, err := os.ReadFile(callLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for line := range strings.Lines(string(calls)) {
+		command, dir, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if command != "version" && dir != module.Path {
+			t.Errorf("terraform %s ran in %s, want %s", command, dir, module.Path)
+		}
+	}
+}
+
+func TestWorkspace_NeedsInspection(t *testing.T) {
+	tests := []struct {
+		name   string
+		module *Module
+		want   bool
+	}{
+		{name: "clean", module: &Module{}},
+		{name: "apply error", module: &Module{Errors: []error{&ModuleError{Operation: "terraform apply"}}}},
+		{name: "remaining resources", module: &Module{RemainingResources: []string{"azurerm_resource_group.rg"}}, want: true},
+		{name: "destroy error", module: &Module{Errors: []error{&ModuleError{Operation: "terraform destroy"}}}, want: true},
+		{name: "destroy timeout", module: &Module{Errors: []error{&ModuleError{Operation: "terraform destroy timeout"}}}, want: true},
+		{name: "verification error", module: &Module{Errors: []error{&ModuleError{Operation: "destroy verification"}}}, want: true},
+		{name: "cleanup error", module: &Module{Errors: []error{&ModuleError{Operation: "cleanup"}}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &workspace{}
+			w.track(&Module{}, tt.module)
+			if got := w.needsInspection(); got != tt.want {
+				t.Errorf("needsInspection() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package validor
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	JournalApplying   = "applying"
+	JournalApplied    = "applied"
+	JournalDestroying = "destroying"
+	JournalDestroyed  = "destroyed"
+)
+
+type JournalEntry struct {
+	RunID   string         `json:"run_id"`
+	Example string         `json:"example"`
+	Path    string         `json:"path"`
+	Phase   string         `json:"phase"`
+	Time    time.Time      `json:"time"`
+	Vars    map[string]any `json:"vars,omitempty"`
+}
+
+// Journal appends one JSON line per phase change, so that a killed run still
+// leaves a record of which examples may hold resources. A nil Journal
+// records nothing.
+type Journal struct {
+	mu    sync.Mutex
+	path  string
+	runID string
+}
+
+func NewJournal(path string) (*Journal, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	return &Journal{path: path, runID: newRunID()}, nil
+}
+
+func newRunID() string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
+
+func (j *Journal) Record(module *Module, phase string) error {
+	if j == nil {
+		return nil
+	}
+
+	path, err := filepath.Abs(module.Path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path of module %s: %w", module.Name, err)
+	}
+	entry := JournalEntry{
+		RunID:   j.runID,
+		Example: module.Name,
+		Path:    path,
+		Phase:   phase,
+		Time:    time.Now().UTC(),
+	}
+	if phase == JournalApplying {
+		entry.Vars = module.Options.Vars
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return file.Close()
+}
+
+func ReadJournal(path string) ([]JournalEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	var entries []JournalEntry
+	var parseErr error
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A run killed mid-write can leave a truncated last line, which
+			// is ignored. Anything malformed before that is an error.
+			parseErr = fmt.Errorf("failed to parse journal line %d: %w", line, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+// Leftovers returns the latest entry of every example whose last recorded
+// phase is not destroyed, most recently applied first.
+func Leftovers(entries []JournalEntry) []JournalEntry {
+	latest := make(map[string]JournalEntry)
+	var order []string
+	for _, entry := range entries {
+		previous, seen := latest[entry.Path]
+		if !seen || entry.Phase == JournalApplying {
+			order = moveToEnd(order, entry.Path)
+		}
+		if entry.Vars == nil && seen && entry.Phase != JournalApplying {
+			entry.Vars = previous.Vars
+		}
+		latest[entry.Path] = entry
+	}
+
+	var leftovers []JournalEntry
+	for i := len(order) - 1; i >= 0; i-- {
+		if entry := latest[order[i]]; entry.Phase != JournalDestroyed {
+			leftovers = append(leftovers, entry)
+		}
+	}
+	return leftovers
+}
+
+func moveToEnd(order []string, path string) []string {
+	for i, p := range order {
+		if p == path {
+			order = append(order[:i], order[i+1:]...)
+			break
+		}
+	}
+	return append(order, path)
+}
+
+// DestroyLeftovers destroys every example the journal at journalPath still
+// records as applied, for example after a killed or -skip-destroy run.
+func DestroyLeftovers(t *testing.T, journalPath string, opts ...Option) {
+	ctx := context.Background()
+	config := setupConfigWithOptions(opts...)
+
+	entries, err := ReadJournal(journalPath)
+	if err != nil {
+		t.Fatal(redError(fmt.Sprintf("Failed to read journal: %v", err)))
+	}
+	retryPolicy, err := resolveRetryPolicy(config)
+	if err != nil {
+		t.Fatal(redError(fmt.Sprintf("Failed to load retry policy: %v", err)))
+	}
+
+	leftovers := Leftovers(entries)
+	if len(leftovers) == 0 {
+		t.Logf("No leftovers found in journal %s", journalPath)
+		return
+	}
+
+	runner := newModuleRunner(config, "")
+	runner.journal = &Journal{path: journalPath, runID: newRunID()}
+
+	modules := make([]*Module, 0, len(leftovers))
+	for _, entry := range leftovers {
+		module := NewModule(entry.Example, entry.Path)
+		// The manifest brings back the env and var_files of the example; the
+		// journal vars include fixture outputs and override the manifest vars.
+		if err := module.LoadManifest(); err != nil {
+			module.recordError(t, "manifest", err)
+		}
+		if len(entry.Vars) > 0 {
+			if module.Options.Vars == nil {
+				module.Options.Vars = make(map[string]any, len(entry.Vars))
+			}
+			maps.Copy(module.Options.Vars, entry.Vars)
+		}
+		module.RetryPolicy = retryPolicy
+		modules = append(modules, module)
+	}
+	runner.toolchain = setupToolchain(ctx, t, config, modules)
+
+	for i, entry := range leftovers {
+		module := modules[i]
+
+		if len(module.Errors) > 0 {
+			t.Fail()
+			continue
+		}
+
+		t.Logf("Destroying leftover example %s (%s in run %s at %s)", entry.Example, entry.Phase, entry.RunID, entry.Time.Format(time.RFC3339))
+		if _, err := runTerraform(ctx, t, module.Options, initArgs(module.Options)...); err != nil {
+			module.recordError(t, "terraform init", err)
+			t.Fail()
+			continue
+		}
+		runner.destroy(ctx, t, module)
+		if len(module.Errors) > 0 {
+			t.Fail()
+		}
+	}
+
+	PrintModuleSummary(t, modules)
+}
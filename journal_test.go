@@ -0,0 +1,234 @@
+package validor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJournal_RecordAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "journal.jsonl")
+	journal, err := NewJournal(path)
+	if err != nil {
+		t.Fatalf("NewJournal() error = %v", err)
+	}
+
+	module := NewModule("default", t.TempDir())
+	module.Options.Vars = map[string]any{"location": "westeurope"}
+	for _, phase := range []string{JournalApplying, JournalApplied} {
+		if err := journal.Record(module, phase); err != nil {
+			t.Fatalf("Record(%s) error = %v", phase, err)
+		}
+	}
+
+	entries, err := ReadJournal(path)
+	if err != nil {
+		t.Fatalf("ReadJournal() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	first := entries[0]
+	if first.RunID == "" || first.RunID != entries[1].RunID {
+		t.Errorf("entries should share a run ID, got %q and %q", first.RunID, entries[1].RunID)
+	}
+	if first.Example != "default" || first.Path != module.Path || first.Phase != JournalApplying || first.Time.IsZero() {
+		t.Errorf("unexpected entry %+v", first)
+	}
+	if first.Vars["location"] != "westeurope" || entries[1].Vars != nil {
+		t.Errorf("variables should only be recorded on apply, got %v and %v", first.Vars, entries[1].Vars)
+	}
+}
+
+func TestJournal_NilRecordsNothing(t *testing.T) {
+	journal, err := NewJournal("")
+	if err != nil || journal != nil {
+		t.Fatalf("NewJournal(\"\") = %v, %v", journal, err)
+	}
+	if err := journal.Record(NewModule("default", t.TempDir()), JournalApplying); err != nil {
+		t.Errorf("Record on nil journal error = %v", err)
+	}
+}
+
+func TestReadJournal_MalformedLines(t *testing.T) {
+	valid := `{"run_id":"r1","example":"a","path":"/a","phase":"applying","time":"2024-01-01T00:00:00Z"}`
+
+	tests := []struct {
+		name    string
+		content string
+		entries int
+		wantErr bool
+	}{
+		{name: "truncated last line", content: valid + "\n{\"run_id\":\"r1\",\"exa", entries: 1},
+		{name: "malformed middle line", content: valid + "\nnot json\n" + valid + "\n", wantErr: true},
+		{name: "blank lines", content: "\n" + valid + "\n\n", entries: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "journal.jsonl")
+			writeFile(t, path, tt.content)
+
+			entries, err := ReadJournal(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadJournal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(entries) != tt.entries {
+				t.Errorf("got %d entries, want %d", len(entries), tt.entries)
+			}
+		})
+	}
+}
+
+func TestLeftovers(t *testing.T) {
+	entry := func(path, phase string, vars map[string]any) JournalEntry {
+		return JournalEntry{Example: filepath.Base(path), Path: path, Phase: phase, Vars: vars}
+	}
+	vars := map[string]any{"rg": "shared"}
+
+	tests := []struct {
+		name    string
+		entries []JournalEntry
+		want    string
+	}{
+		{
+			name: "destroyed examples are not leftovers",
+			entries: []JournalEntry{
+				entry("/a", JournalApplying, nil), entry("/a", JournalApplied, nil),
+				entry("/a", JournalDestroying, nil), entry("/a", JournalDestroyed, nil),
+			},
+			want: "",
+		},
+		{
+			name: "most recently applied first",
+			entries: []JournalEntry{
+				entry("/fixture", JournalApplying, nil), entry("/fixture", JournalApplied, nil),
+				entry("/a", JournalApplying, vars), entry("/b", JournalApplying, nil),
+				entry("/a", JournalApplied, nil), entry("/b", JournalDestroying, nil),
+			},
+			want: "b:destroying,a:applied,fixture:applied",
+		},
+		{
+			name: "reapplied after destroy",
+			entries: []JournalEntry{
+				entry("/a", JournalApplying, nil), entry("/a", JournalDestroyed, nil),
+				entry("/b", JournalApplying, nil), entry("/a", JournalApplying, nil),
+			},
+			want: "a:applying,b:applying",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, leftover := range Leftovers(tt.entries) {
+				got = append(got, leftover.Example+":"+leftover.Phase)
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("Leftovers() = %s, want %s", strings.Join(got, ","), tt.want)
+			}
+		})
+	}
+
+	leftovers := Leftovers([]JournalEntry{entry("/a", JournalApplying, vars), entry("/a", JournalApplied, nil)})
+	if leftovers[0].Vars["rg"] != "shared" {
+		t.Errorf("leftover should keep the variables of the apply, got %v", leftovers[0].Vars)
+	}
+}
+
+func TestDestroyLeftovers(t *testing.T) {
+	journalPath := filepath.Join(t.TempDir(), "journal.jsonl")
+	callLog := filepath.Join(t.TempDir(), "calls")
+
+	script := writeScript(t, `echo "$1" >> `+callLog+`
+exit 0
+`)
+	t.Setenv("PATH", filepath.Dir(script)+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	module := NewModule("default", t.TempDir())
+	module.applyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+		return nil
+	}
+
+	t.Run("examples", func(t *testing.T) {
+		config := NewConfig(WithSkipDestroy(true), WithJournalPath(journalPath))
+		runModuleTests(t, []*Module{module}, false, config, nil, "registry")
+	})
+
+	entries, err := ReadJournal(journalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leftovers := Leftovers(entries); len(leftovers) != 1 || leftovers[0].Phase != JournalApplied {
+		t.Fatalf("expected one applied leftover, got %+v", leftovers)
+	}
+
+	if err := os.Remove(callLog); err != nil {
+		t.Fatal(err)
+	}
+	t.Run("leftovers", func(t *testing.T) {
+		DestroyLeftovers(t, journalPath, WithDestroyTimeout(time.Minute))
+	})
+
+	calls, err := os.ReadFile(callLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "version\ninit\ndestroy\nstate\n"; string(calls) != want {
+		t.Errorf("terraform calls = %q, want %q", calls, want)
+	}
+
+	entries, err = ReadJournal(journalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leftovers := Leftovers(entries); len(leftovers) != 0 {
+		t.Errorf("expected no leftovers after DestroyLeftovers, got %+v", leftovers)
+	}
+}
+
+func TestDestroyLeftovers_LoadsManifest(t *testing.T) {
+	examplePath := t.TempDir()
+	writeFile(t, filepath.Join(examplePath, ManifestFileName), `vars:
+  location: westeurope
+  name: from-manifest
+var_files:
+  - extra.tfvars
+env:
+  ARM_SUBSCRIPTION_ID: sub-123
+`)
+
+	journalPath := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := NewJournal(journalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	module := NewModule("default", examplePath)
+	module.Options.Vars = map[string]any{"name": "from-journal"}
+	if err := journal.Record(module, JournalApplying); err != nil {
+		t.Fatal(err)
+	}
+
+	callLog := filepath.Join(t.TempDir(), "calls")
+	script := writeScript(t, `[ "$1" = destroy ] && echo "$ARM_SUBSCRIPTION_ID $*" >> `+callLog+`
+exit 0
+`)
+	t.Setenv("PATH", filepath.Dir(script)+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	t.Run("leftovers", func(t *testing.T) {
+		DestroyLeftovers(t, journalPath, WithDestroyTimeout(time.Minute))
+	})
+
+	calls, err := os.ReadFile(callLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"sub-123 ", "-var-file extra.tfvars", "-var name=from-journal", "-var location=westeurope"} {
+		if !strings.Contains(string(calls), want) {
+			t.Errorf("destroy call %q does not contain %q", calls, want)
+		}
+	}
+}
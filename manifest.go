@@ -0,0 +1,157 @@
+package validor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const ManifestFileName = "validor.yaml"
+
+type Manifest struct {
+	DependsOn   []string          `yaml:"depends_on"`
+	Vars        map[string]any    `yaml:"vars"`
+	VarFiles    []string          `yaml:"var_files"`
+	Env         map[string]string `yaml:"env"`
+	Timeouts    ManifestTimeouts  `yaml:"timeouts"`
+	Tags        []string          `yaml:"tags"`
+	Skip        bool              `yaml:"skip"`
+	SkipReason  string            `yaml:"skip_reason"`
+	RequiredEnv []string          `yaml:"required_env"`
+	ExpectError string            `yaml:"expect_error"`
+	Assertions  []OutputAssertion `yaml:"assertions"`
+	WaivedRules []string          `yaml:"waived_rules"`
+}
+
+// ManifestTimeouts override the -example-timeout, -apply-timeout and
+// -destroy-timeout flags for one example.
+type ManifestTimeouts struct {
+	Example time.Duration `yaml:"example"`
+	Apply   time.Duration `yaml:"apply"`
+	Destroy time.Duration `yaml:"destroy"`
+}
+
+func LoadManifest(dir string) (*Manifest, error) {
+	path := filepath.Join(dir, ManifestFileName)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Manifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", path, err)
+	}
+
+	manifest := &Manifest{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(manifest); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	if manifest.ExpectError != "" {
+		if _, err := regexp.Compile(manifest.ExpectError); err != nil {
+			return nil, fmt.Errorf("invalid expect_error in manifest %s: %w", path, err)
+		}
+	}
+	for _, assertion := range manifest.Assertions {
+		if err := assertion.validate(); err != nil {
+			return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+		}
+	}
+	return manifest, nil
+}
+
+func (m *Module) LoadManifest() error {
+	manifest, err := LoadManifest(m.Path)
+	if err != nil {
+		return err
+	}
+	m.Manifest = manifest
+	m.applyManifest()
+	return nil
+}
+
+// applyManifest merges the manifest variables, var files and environment
+// into the terraform options. Values already set on the options win.
+func (m *Module) applyManifest() {
+	manifest := m.Manifest
+	if len(manifest.Vars) > 0 {
+		vars := maps.Clone(manifest.Vars)
+		maps.Copy(vars, m.Options.Vars)
+		m.Options.Vars = vars
+	}
+	m.Options.VarFiles = append(m.Options.VarFiles, manifest.VarFiles...)
+	if len(manifest.Env) > 0 {
+		env := maps.Clone(manifest.Env)
+		maps.Copy(env, m.Options.EnvVars)
+		m.Options.EnvVars = env
+	}
+}
+
+// skipReason returns why the example should not run, or an empty string.
+func (m *Module) skipReason() string {
+	if m.Manifest == nil {
+		return ""
+	}
+	if m.Manifest.Skip {
+		if m.Manifest.SkipReason == "" {
+			return "skipped in " + ManifestFileName
+		}
+		return m.Manifest.SkipReason
+	}
+	for _, name := range m.Manifest.RequiredEnv {
+		if _, ok := m.Options.EnvVars[name]; ok {
+			continue
+		}
+		if os.Getenv(name) == "" {
+			return "required environment variable " + name + " is not set"
+		}
+	}
+	return ""
+}
+
+// timeoutConfig returns config with the timeouts of the manifest applied.
+func (m *Module) timeoutConfig(config *Config) *Config {
+	if m.Manifest == nil || m.Manifest.Timeouts == (ManifestTimeouts{}) {
+		return config
+	}
+	overridden := *config
+	timeouts := m.Manifest.Timeouts
+	if timeouts.Example > 0 {
+		overridden.ExampleTimeout = timeouts.Example
+	}
+	if timeouts.Apply > 0 {
+		overridden.ApplyTimeout = timeouts.Apply
+	}
+	if timeouts.Destroy > 0 {
+		overridden.DestroyTimeout = timeouts.Destroy
+	}
+	return &overridden
+}
+
+func (m *Module) dependsOn() []string {
+	if m.Manifest == nil {
+		return nil
+	}
+	return m.Manifest.DependsOn
+}
+
+func loadManifests(modules []*Module) error {
+	for _, module := range modules {
+		if module.Manifest != nil {
+			continue
+		}
+		if err := module.LoadManifest(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package validor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLoadManifest(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   *string
+		dependsOn []string
+		wantErr   bool
+	}{
+		{name: "missing file", content: nil},
+		{name: "empty file", content: ptr("")},
+		{name: "depends on", content: ptr("depends_on:\n  - network\n  - keyvault\n"), dependsOn: []string{"network", "keyvault"}},
+		{name: "unknown field", content: ptr("depend_on:\n  - network\n"), wantErr: true},
+		{name: "invalid yaml", content: ptr("depends_on: [network\n"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.content != nil {
+				if err := os.WriteFile(filepath.Join(dir, ManifestFileName), []byte(*tt.content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			manifest, err := LoadManifest(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !slices.Equal(manifest.DependsOn, tt.dependsOn) {
+				t.Errorf("DependsOn = %v, want %v", manifest.DependsOn, tt.dependsOn)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestLoadManifest_AllFields(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ManifestFileName), `
+depends_on: [network]
+vars:
+  location: westeurope
+  tags:
+    env: test
+var_files: [test.tfvars]
+env:
+  TF_LOG: INFO
+timeouts:
+  example: 45m
+  apply: 30m
+  destroy: 10m
+tags: [network, slow]
+skip: true
+skip_reason: quota exhausted in the test subscription
+required_env: [ARM_SUBSCRIPTION_ID]
+expect_error: 'Invalid value for variable'
+`)
+
+	manifest, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+
+	want := &Manifest{
+		DependsOn:   []string{"network"},
+		Vars:        map[string]any{"location": "westeurope", "tags": map[string]any{"env": "test"}},
+		VarFiles:    []string{"test.tfvars"},
+		Env:         map[string]string{"TF_LOG": "INFO"},
+		Timeouts:    ManifestTimeouts{Example: 45 * time.Minute, Apply: 30 * time.Minute, Destroy: 10 * time.Minute},
+		Tags:        []string{"network", "slow"},
+		Skip:        true,
+		SkipReason:  "quota exhausted in the test subscription",
+		RequiredEnv: []string{"ARM_SUBSCRIPTION_ID"},
+		ExpectError: "Invalid value for variable",
+	}
+	if !reflect.DeepEqual(manifest, want) {
+		t.Errorf("LoadManifest() = %+v, want %+v", manifest, want)
+	}
+}
+
+func TestModule_LoadManifestMergesOptions(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ManifestFileName), `
+vars:
+  location: westeurope
+  name: from-manifest
+var_files: [test.tfvars]
+env:
+  TF_LOG: INFO
+  ARM_USE_OIDC: "true"
+`)
+
+	module := NewModule("default", dir)
+	module.Options.Vars = map[string]any{"name": "from-options"}
+	module.Options.EnvVars = map[string]string{"TF_LOG": "DEBUG"}
+
+	if err := module.LoadManifest(); err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+
+	if want := map[string]any{"location": "westeurope", "name": "from-options"}; !reflect.DeepEqual(module.Options.Vars, want) {
+		t.Errorf("Vars = %v, want %v", module.Options.Vars, want)
+	}
+	if !slices.Equal(module.Options.VarFiles, []string{"test.tfvars"}) {
+		t.Errorf("VarFiles = %v", module.Options.VarFiles)
+	}
+	if want := map[string]string{"TF_LOG": "DEBUG", "ARM_USE_OIDC": "true"}; !reflect.DeepEqual(module.Options.EnvVars, want) {
+		t.Errorf("EnvVars = %v, want %v", module.Options.EnvVars, want)
+	}
+}
+
+func TestModule_SkipReason(t *testing.T) {
+	t.Setenv("VALIDOR_SET", "1")
+
+	tests := []struct {
+		name     string
+		manifest *Manifest
+		env      map[string]string
+		want     string
+	}{
+		{name: "no manifest", want: ""},
+		{name: "skip with reason", manifest: &Manifest{Skip: true, SkipReason: "flaky API"}, want: "flaky API"},
+		{name: "skip without reason", manifest: &Manifest{Skip: true}, want: "skipped in validor.yaml"},
+		{name: "required env set", manifest: &Manifest{RequiredEnv: []string{"VALIDOR_SET"}}, want: ""},
+		{name: "required env from manifest env", manifest: &Manifest{RequiredEnv: []string{"VALIDOR_MANIFEST"}}, env: map[string]string{"VALIDOR_MANIFEST": "1"}, want: ""},
+		{name: "required env missing", manifest: &Manifest{RequiredEnv: []string{"VALIDOR_SET", "VALIDOR_MISSING"}}, want: "required environment variable VALIDOR_MISSING is not set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			module := NewModule("default", "")
+			module.Manifest = tt.manifest
+			module.Options.EnvVars = tt.env
+			if got := module.skipReason(); got != tt.want {
+				t.Errorf("skipReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModule_TimeoutConfig(t *testing.T) {
+	config := &Config{ExampleTimeout: time.Hour, ApplyTimeout: 40 * time.Minute}
+
+	module := NewModule("default", "")
+	if got := module.timeoutConfig(config); got != config {
+		t.Error("without a manifest the config should be returned as is")
+	}
+
+	module.Manifest = &Manifest{Timeouts: ManifestTimeouts{Apply: 20 * time.Minute, Destroy: 5 * time.Minute}}
+	got := module.timeoutConfig(config)
+	if got.ExampleTimeout != time.Hour || got.ApplyTimeout != 20*time.Minute || got.DestroyTimeout != 5*time.Minute {
+		t.Errorf("timeoutConfig() = %+v", got)
+	}
+	if config.ApplyTimeout != 40*time.Minute {
+		t.Error("timeoutConfig() must not modify the shared config")
+	}
+}
@@ -0,0 +1,183 @@
+package validor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// resolveVersionMatrix resolves every entry of config.TerraformVersions to a
+// binary. An entry is either a path to a binary or a version looked up in
+// config.TerraformBinariesDir.
+func resolveVersionMatrix(ctx context.Context, t testLogger, config *Config) ([]Toolchain, error) {
+	toolchains := make([]Toolchain, 0, len(config.TerraformVersions))
+	for _, entry := range config.TerraformVersions {
+		binary, err := resolveVersionBinary(entry, config.TerraformBinariesDir)
+		if err != nil {
+			return nil, err
+		}
+		toolchain := detectToolchain(ctx, t, binary)
+		if isVersion(entry) && toolchain.Version != "" && toolchain.Version != entry {
+			t.Logf("Warning: %s reports version %s, expected %s", binary, toolchain.Version, entry)
+		}
+		if toolchain.Version == "" && isVersion(entry) {
+			toolchain.Version = entry
+		}
+		toolchains = append(toolchains, toolchain)
+	}
+	return toolchains, nil
+}
+
+// resolveVersionBinary supports the tfenv (<dir>/<version>/terraform) and
+// tfswitch (<dir>/terraform_<version>) layouts, for terraform and tofu.
+func resolveVersionBinary(entry, dir string) (string, error) {
+	if strings.ContainsRune(entry, '/') || strings.ContainsRune(entry, filepath.Separator) {
+		if info, err := os.Stat(entry); err != nil || info.IsDir() {
+			return "", fmt.Errorf("terraform binary %s not found", entry)
+		}
+		return entry, nil
+	}
+	if dir == "" {
+		return "", fmt.Errorf("terraform version %s needs a binaries directory", entry)
+	}
+
+	candidates := []string{
+		filepath.Join(dir, entry, defaultTerraformBinary),
+		filepath.Join(dir, entry, openTofuBinary),
+		filepath.Join(dir, defaultTerraformBinary+"_"+entry),
+		filepath.Join(dir, openTofuBinary+"_"+entry),
+	}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no terraform or tofu binary for version %s in %s", entry, dir)
+}
+
+func isVersion(entry string) bool {
+	return entry != "" && entry[0] >= '0' && entry[0] <= '9'
+}
+
+// Label names the toolchain in subtests and the version matrix.
+func (tc Toolchain) Label() string {
+	label := tc.Version
+	if label == "" {
+		label = filepath.Base(tc.Binary)
+	}
+	if strings.Contains(filepath.Base(tc.Binary), openTofuBinary) && !strings.HasPrefix(label, openTofuBinary) {
+		label = openTofuBinary + "-" + label
+	}
+	return label
+}
+
+// withToolchain returns a copy of the module that runs with toolchain. The
+// copy is named <example>/<label>, matching its subtest.
+func (m *Module) withToolchain(toolchain Toolchain) (*Module, error) {
+	options, err := m.Options.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone terraform options of module %s: %w", m.Name, err)
+	}
+	options.TerraformBinary = toolchain.Binary
+
+	variant := &Module{
+		Name:         m.Name + "/" + toolchain.Label(),
+		Path:         m.Path,
+		Options:      options,
+		RetryPolicy:  m.RetryPolicy,
+		Manifest:     m.Manifest,
+		Toolchain:    &toolchain,
+		example:      m.Name,
+		applyHook:    m.applyHook,
+		destroyHook:  m.destroyHook,
+		cleanupHook:  m.cleanupHook,
+		planHook:     m.planHook,
+		validateHook: m.validateHook,
+	}
+	return variant, nil
+}
+
+// expandVersionMatrix returns one variant per toolchain for every module.
+// Variants of the same example share its directory and state, so they cannot
+// be combined with dependencies between examples.
+func expandVersionMatrix(modules []*Module, toolchains []Toolchain) (map[string][]*Module, []*Module, error) {
+	variants := make(map[string][]*Module, len(modules))
+	var all []*Module
+	for _, module := range modules {
+		if len(module.dependsOn()) > 0 {
+			return nil, nil, fmt.Errorf("example %s declares depends_on, which is not supported with a version matrix", module.Name)
+		}
+		for _, toolchain := range toolchains {
+			variant, err := module.withToolchain(toolchain)
+			if err != nil {
+				return nil, nil, err
+			}
+			variants[module.Name] = append(variants[module.Name], variant)
+			all = append(all, variant)
+		}
+	}
+	return variants, all, nil
+}
+
+func (m *Module) status() string {
+	switch {
+	case m.SkipReason != "":
+		return "SKIP"
+	case m.Interrupted:
+		return "INTERRUPTED"
+	case len(m.Errors) > 0:
+		return "FAIL"
+	case m.BlockedReason != "":
+		return "BLOCKED"
+	default:
+		return "PASS"
+	}
+}
+
+func printVersionMatrix(tb testLogger, modules []*Module) {
+	tb.Helper()
+
+	var examples, labels []string
+	results := make(map[string]map[string]string)
+	for _, module := range modules {
+		if module.Toolchain == nil {
+			continue
+		}
+		label := module.Toolchain.Label()
+		if results[module.example] == nil {
+			results[module.example] = make(map[string]string)
+			examples = append(examples, module.example)
+		}
+		if !slices.Contains(labels, label) {
+			labels = append(labels, label)
+		}
+		results[module.example][label] = module.status()
+	}
+	if len(examples) == 0 {
+		return
+	}
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "example\t%s\n", strings.Join(labels, "\t"))
+	for _, example := range examples {
+		row := make([]string, len(labels))
+		for i, label := range labels {
+			row[i] = results[example][label]
+			if row[i] == "" {
+				row[i] = "-"
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\n", example, strings.Join(row, "\t"))
+	}
+	w.Flush()
+
+	tb.Log("Version matrix:")
+	for line := range strings.SplitSeq(strings.TrimRight(sb.String(), "\n"), "\n") {
+		tb.Log("  " + line)
+	}
+}
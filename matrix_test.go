@@ -0,0 +1,139 @@
+package validor
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestResolveVersionBinary(t *testing.T) {
+	dir := t.TempDir()
+	tfenv := writeScriptAt(t, filepath.Join(dir, "1.9.8", "terraform"), "exit 0\n")
+	tfswitch := writeScriptAt(t, filepath.Join(dir, "terraform_1.10.0"), "exit 0\n")
+	tofu := writeScriptAt(t, filepath.Join(dir, "tofu_1.8.5"), "exit 0\n")
+
+	tests := []struct {
+		name    string
+		entry   string
+		dir     string
+		want    string
+		wantErr bool
+	}{
+		{name: "tfenv layout", entry: "1.9.8", dir: dir, want: tfenv},
+		{name: "tfswitch layout", entry: "1.10.0", dir: dir, want: tfswitch},
+		{name: "tofu", entry: "1.8.5", dir: dir, want: tofu},
+		{name: "path", entry: tfswitch, want: tfswitch},
+		{name: "missing path", entry: filepath.Join(dir, "nope"), wantErr: true},
+		{name: "missing version", entry: "1.5.0", dir: dir, wantErr: true},
+		{name: "version without directory", entry: "1.9.8", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveVersionBinary(tt.entry, tt.dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveVersionBinary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveVersionBinary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolchain_Label(t *testing.T) {
+	tests := []struct {
+		toolchain Toolchain
+		want      string
+	}{
+		{Toolchain{Binary: "/bin/1.9.8/terraform", Version: "1.9.8"}, "1.9.8"},
+		{Toolchain{Binary: "/bin/tofu_1.8.5", Version: "1.8.5"}, "tofu-1.8.5"},
+		{Toolchain{Binary: "/opt/custom-terraform"}, "custom-terraform"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.toolchain.Label(); got != tt.want {
+			t.Errorf("Label() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestExpandVersionMatrix(t *testing.T) {
+	toolchains := []Toolchain{{Binary: "/a/terraform", Version: "1.9.8"}, {Binary: "/b/terraform", Version: "1.10.0"}}
+
+	module := NewModule("default", t.TempDir())
+	variants, all, err := expandVersionMatrix([]*Module{module}, toolchains)
+	if err != nil {
+		t.Fatalf("expandVersionMatrix() error = %v", err)
+	}
+	if got := moduleNames(all); got != "default/1.9.8,default/1.10.0" {
+		t.Errorf("variants = %s", got)
+	}
+	if variant := variants["default"][1]; variant.Options.TerraformBinary != "/b/terraform" || variant.Options == module.Options {
+		t.Errorf("variant should have its own options with the toolchain binary, got %q", variant.Options.TerraformBinary)
+	}
+
+	if _, _, err := expandVersionMatrix([]*Module{moduleWithDeps("app", "vnet")}, toolchains); err == nil {
+		t.Error("expected an error for an example with dependencies")
+	}
+}
+
+func TestPrintModuleSummary_VersionMatrix(t *testing.T) {
+	toolchains := []Toolchain{{Binary: "terraform", Version: "1.9.8"}, {Binary: "terraform", Version: "1.10.0"}}
+	_, modules, err := expandVersionMatrix([]*Module{NewModule("default", ""), NewModule("complete", "")}, toolchains)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modules[1].Errors = []error{context.Canceled}
+
+	tb := &mockTB{}
+	PrintModuleSummary(tb, modules)
+
+	output := strings.Join(tb.logs, "\n")
+	for _, want := range []string{
+		"example   1.9.8  1.10.0",
+		"default   PASS   FAIL",
+		"complete  PASS   PASS",
+		"Module default/1.10.0 failed",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("summary missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestRunModuleTests_VersionMatrix(t *testing.T) {
+	dir := t.TempDir()
+	for _, version := range []string{"1.9.8", "1.10.0"} {
+		writeScriptAt(t, filepath.Join(dir, "terraform_"+version), `echo '{"terraform_version":"`+version+`"}'`+"\n")
+	}
+
+	var mu sync.Mutex
+	var runs []string
+	module := NewModule("default", t.TempDir())
+	module.applyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+		mu.Lock()
+		defer mu.Unlock()
+		runs = append(runs, tb.Name()+" "+filepath.Base(m.Options.TerraformBinary))
+		return nil
+	}
+	module.destroyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+		return nil
+	}
+
+	config := NewConfig(WithTerraformVersions("1.9.8", "1.10.0"), WithTerraformBinariesDir(dir))
+	t.Run("examples", func(t *testing.T) {
+		runModuleTests(t, []*Module{module}, true, config, nil, "registry")
+	})
+
+	want := []string{
+		t.Name() + "/examples/default/1.9.8 terraform_1.9.8",
+		t.Name() + "/examples/default/1.10.0 terraform_1.10.0",
+	}
+	if !slices.Equal(runs, want) {
+		t.Errorf("runs = %v, want %v", runs, want)
+	}
+}
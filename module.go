@@ -2,25 +2,42 @@ package validor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"slices"
+	"strings"
 	"testing"
 
 	"github.com/gruntwork-io/terratest/modules/terraform"
 )
 
 type Module struct {
-	Name        string
-	Path        string
-	Options     *terraform.Options
-	Errors      []error
-	ApplyFailed bool
-
-	applyHook   func(ctx context.Context, t *testing.T, m *Module) error
-	destroyHook func(ctx context.Context, t *testing.T, m *Module) error
-	cleanupHook func(ctx context.Context, t *testing.T, m *Module) error
+	Name               string
+	Path               string
+	Options            *terraform.Options
+	Errors             []error
+	ApplyFailed        bool
+	PendingChanges     []string
+	RemainingResources []string
+	PlanSummary        *PlanSummary
+	Validated          bool
+	RetryPolicy        *RetryPolicy
+	Manifest           *Manifest
+	BlockedReason      string
+	Interrupted        bool
+	SkipReason         string
+	Toolchain          *Toolchain
+	FailedAsExpected   bool
+
+	applyHook    func(ctx context.Context, t *testing.T, m *Module) error
+	destroyHook  func(ctx context.Context, t *testing.T, m *Module) error
+	cleanupHook  func(ctx context.Context, t *testing.T, m *Module) error
+	planHook     func(ctx context.Context, t *testing.T, m *Module) (*terraform.PlanStruct, error)
+	validateHook func(ctx context.Context, t *testing.T, m *Module) error
+
+	// example is the name of the example a version matrix variant runs.
+	example string
 }
 
 type testLogger interface {
@@ -52,7 +69,7 @@ func NewModule(name, path string) *Module {
 		Options: &terraform.Options{
 			TerraformDir:    path,
 			NoColor:         true,
-			TerraformBinary: "terraform",
+			TerraformBinary: defaultTerraformBinary,
 		},
 	}
 }
@@ -60,40 +77,61 @@ func NewModule(name, path string) *Module {
 func (mm *ModuleManager) DiscoverModules() ([]*Module, error) {
 	var modules []*Module
 
-	entries, err := os.ReadDir(mm.BaseExamplesPath)
+	names, err := listExamples(mm.BaseExamplesPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read examples directory: %w", err)
+		return nil, err
+	}
+	if mm.Config != nil {
+		if _, err := resolvePatterns(mm.Config.ExceptionList, names); err != nil {
+			return nil, fmt.Errorf("invalid -exception: %w", err)
+		}
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			moduleName := entry.Name()
-			if mm.Config != nil && slices.Contains(mm.Config.ExceptionList, moduleName) {
-				fmt.Printf("Skipping module %s as it is in the exception list\n", moduleName)
-				continue
-			}
-			modulePath := filepath.Join(mm.BaseExamplesPath, moduleName)
-			modules = append(modules, NewModule(moduleName, modulePath))
+	for _, moduleName := range names {
+		if mm.Config != nil && matchesAnyPattern(mm.Config.ExceptionList, moduleName) {
+			fmt.Printf("Skipping module %s as it is in the exception list\n", moduleName)
+			continue
+		}
+		modulePath := filepath.Join(mm.BaseExamplesPath, moduleName)
+		module := NewModule(moduleName, modulePath)
+		if err := module.LoadManifest(); err != nil {
+			return nil, err
 		}
+		modules = append(modules, module)
 	}
 
-	return modules, nil
+	modules, err = filterModulesByTags(modules, mm.Config)
+	if err != nil {
+		return nil, err
+	}
+	return filterModulesByChanges(modules, mm.Config, mm.BaseExamplesPath)
 }
 
 func (m *Module) Apply(ctx context.Context, t *testing.T) error {
 	t.Helper()
 
 	if m.applyHook != nil {
-		return m.applyHook(ctx, t, m)
+		err := m.applyHook(ctx, t, m)
+		if m.expectsFailure() {
+			return m.checkExpectedFailure(t, "terraform apply", err)
+		}
+		return err
 	}
 
 	t.Logf("Applying Terraform module: %s", m.Name)
-	terraform.WithDefaultRetryableErrors(t, m.Options)
 
-	_, err := terraform.InitAndApplyE(t, m.Options)
+	_, err := m.retryPolicy().do(ctx, t, "terraform apply for module "+m.Name, func() (string, error) {
+		if _, err := runTerraform(ctx, t, m.Options, initArgs(m.Options)...); err != nil {
+			return "", err
+		}
+		return runTerraform(ctx, t, m.Options, applyArgs(m.Options)...)
+	})
+	if m.expectsFailure() && ctx.Err() == nil {
+		return m.checkExpectedFailure(t, "terraform apply", err)
+	}
 	if err != nil {
 		m.ApplyFailed = true
-		wrappedErr := &ModuleError{ModuleName: m.Name, Operation: "terraform apply", Err: err}
+		wrappedErr := &ModuleError{ModuleName: m.Name, Operation: timeoutOperation("terraform apply", err), Err: err}
 		m.Errors = append(m.Errors, wrappedErr)
 		t.Log(redError(wrappedErr.Error()))
 		return wrappedErr
@@ -124,21 +162,90 @@ func (m *Module) Destroy(ctx context.Context, t *testing.T) error {
 
 	t.Logf("Destroying Terraform module: %s", m.Name)
 
-	_, destroyErr := terraform.DestroyE(t, m.Options)
+	_, destroyErr := m.retryPolicy().do(ctx, t, "terraform destroy for module "+m.Name, func() (string, error) {
+		return runTerraform(ctx, t, m.Options, destroyArgs(m.Options)...)
+	})
 
 	if destroyErr != nil && !m.ApplyFailed {
-		wrappedErr := &ModuleError{ModuleName: m.Name, Operation: "terraform destroy", Err: destroyErr}
+		wrappedErr := &ModuleError{ModuleName: m.Name, Operation: timeoutOperation("terraform destroy", destroyErr), Err: destroyErr}
 		m.Errors = append(m.Errors, wrappedErr)
 		t.Log(redError(wrappedErr.Error()))
 	}
 
+	var verifyErr error
+	if destroyErr == nil {
+		verifyErr = m.VerifyDestroyed(ctx, t)
+		if len(m.RemainingResources) > 0 {
+			t.Logf("Keeping state files in %s for inspection", m.Options.TerraformDir)
+			return verifyErr
+		}
+	}
+
 	if err := m.Cleanup(ctx, t); err != nil && !m.ApplyFailed {
 		wrappedErr := &ModuleError{ModuleName: m.Name, Operation: "cleanup", Err: err}
 		m.Errors = append(m.Errors, wrappedErr)
 		t.Log(redError(wrappedErr.Error()))
 	}
 
-	return destroyErr
+	if destroyErr != nil {
+		return destroyErr
+	}
+	return verifyErr
+}
+
+func (m *Module) VerifyDestroyed(ctx context.Context, t *testing.T) error {
+	t.Helper()
+
+	m.RemainingResources = nil
+	err := ctx.Err()
+	var resources []string
+	if err == nil {
+		resources, err = m.stateResources(ctx, t)
+	}
+	if err != nil {
+		if m.ApplyFailed {
+			return err
+		}
+		return m.recordError(t, "destroy verification", err)
+	}
+
+	m.RemainingResources = resources
+	if len(m.RemainingResources) == 0 {
+		return nil
+	}
+
+	return m.recordError(t, "destroy verification", fmt.Errorf(
+		"state is not empty after destroy, %d resource(s) remain: %s",
+		len(m.RemainingResources), strings.Join(m.RemainingResources, ", ")))
+}
+
+func (m *Module) stateResources(ctx context.Context, t testLogger) ([]string, error) {
+	t.Helper()
+
+	output, err := runTerraform(ctx, t, m.Options, "state", "list")
+	if isNoStateError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list state: %w", err)
+	}
+
+	var resources []string
+	for line := range strings.Lines(output) {
+		if address := strings.TrimSpace(line); address != "" {
+			resources = append(resources, address)
+		}
+	}
+	return resources, nil
+}
+
+// noStateMessage is how terraform state list reports a directory that never
+// wrote state, e.g. because apply was rejected during planning.
+const noStateMessage = "No state file was found"
+
+func isNoStateError(err error) bool {
+	var cmdErr *TerraformCommandError
+	return errors.As(err, &cmdErr) && strings.Contains(cmdErr.Stderr, noStateMessage)
 }
 
 func (m *Module) Cleanup(ctx context.Context, t *testing.T) error {
@@ -171,29 +278,116 @@ func (m *Module) Cleanup(ctx context.Context, t *testing.T) error {
 	return nil
 }
 
+func (m *Module) retryPolicy() *RetryPolicy {
+	if m.RetryPolicy != nil {
+		return m.RetryPolicy
+	}
+	return DefaultRetryPolicy()
+}
+
+func (m *Module) recordError(t testLogger, operation string, err error) error {
+	t.Helper()
+
+	wrappedErr := &ModuleError{ModuleName: m.Name, Operation: operation, Err: err}
+	m.Errors = append(m.Errors, wrappedErr)
+	t.Log(redError(wrappedErr.Error()))
+	return wrappedErr
+}
+
+func skippedSuffix(skipped []*Module) string {
+	if len(skipped) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", %d skipped", len(skipped))
+}
+
 func PrintModuleSummary(tb testLogger, modules []*Module) {
 	tb.Helper()
 
-	var failedModules []*Module
+	var failedModules, blockedModules, interruptedModules, skippedModules []*Module
 	for _, module := range modules {
-		if len(module.Errors) > 0 {
+		if module.SkipReason != "" {
+			skippedModules = append(skippedModules, module)
+		} else if module.Interrupted {
+			interruptedModules = append(interruptedModules, module)
+		} else if len(module.Errors) > 0 {
 			failedModules = append(failedModules, module)
+		} else if module.BlockedReason != "" {
+			blockedModules = append(blockedModules, module)
+		}
+	}
+
+	ran := len(modules) - len(skippedModules)
+	planned, validated := ran > 0, ran > 0
+	for _, module := range modules {
+		if module.SkipReason != "" {
+			continue
+		}
+		if module.PlanSummary != nil {
+			tb.Logf("Module %s plan: %s", module.Name, module.PlanSummary)
+		} else {
+			planned = false
+		}
+		if !module.Validated {
+			validated = false
 		}
 	}
 
-	if len(failedModules) > 0 {
+	printVersionMatrix(tb, modules)
+
+	for _, module := range skippedModules {
+		tb.Logf("Module %s skipped: %s", module.Name, module.SkipReason)
+	}
+	for _, module := range modules {
+		if module.FailedAsExpected && len(module.Errors) == 0 {
+			tb.Logf("Module %s failed as expected", module.Name)
+		}
+	}
+	for _, module := range blockedModules {
+		tb.Logf("Module %s blocked: %s", module.Name, module.BlockedReason)
+	}
+	for _, module := range interruptedModules {
+		tb.Log(redError("Module " + module.Name + " interrupted"))
+		for i, err := range module.Errors {
+			tb.Log(redError(fmt.Sprintf("  %d. %v", i+1, err)))
+		}
+	}
+
+	if len(failedModules) > 0 || len(blockedModules) > 0 || len(interruptedModules) > 0 {
 		for _, module := range failedModules {
 			tb.Log(redError("Module " + module.Name + " failed with errors:"))
 			for i, err := range module.Errors {
 				errText := fmt.Sprintf("  %d. %v", i+1, err)
 				tb.Log(redError(errText))
 			}
+			if len(module.PendingChanges) > 0 {
+				tb.Log(redError("  Pending changes after apply:"))
+				for _, change := range module.PendingChanges {
+					tb.Log(redError("    ~ " + change))
+				}
+			}
+			if len(module.RemainingResources) > 0 {
+				tb.Log(redError("  Resources left in state after destroy:"))
+				for _, address := range module.RemainingResources {
+					tb.Log(redError("    - " + address))
+				}
+			}
 			tb.Log("")
 		}
 
 		totalText := fmt.Sprintf("TOTAL: %d of %d modules failed", len(failedModules), len(modules))
+		if len(blockedModules) > 0 {
+			totalText += fmt.Sprintf(", %d blocked", len(blockedModules))
+		}
+		if len(interruptedModules) > 0 {
+			totalText += fmt.Sprintf(", %d interrupted", len(interruptedModules))
+		}
 		tb.Log(redError(totalText))
+	} else if planned {
+		tb.Logf("\n==== SUCCESS: All %d modules planned successfully%s ====", ran, skippedSuffix(skippedModules))
+	} else if validated {
+		tb.Logf("\n==== SUCCESS: All %d modules validated successfully%s ====", ran, skippedSuffix(skippedModules))
 	} else {
-		tb.Logf("\n==== SUCCESS: All %d modules applied and destroyed successfully ====", len(modules))
+		tb.Logf("\n==== SUCCESS: All %d modules applied and destroyed successfully%s ====", ran, skippedSuffix(skippedModules))
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gruntwork-io/terratest/modules/terraform"
@@ -69,6 +70,9 @@ func TestModuleManager_DiscoverModules(t *testing.T) {
 		if err := os.Mkdir(modPath, 0755); err != nil {
 			t.Fatalf("Failed to create test directory: %v", err)
 		}
+		if err := os.WriteFile(filepath.Join(modPath, "main.tf"), nil, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
 	}
 
 	if err := os.WriteFile(filepath.Join(tmpDir, "readme.txt"), []byte("test"), 0644); err != nil {
@@ -321,3 +325,98 @@ func TestModule_DestroyErrors(t *testing.T) {
 		t.Fatalf("expected error messages to be populated, got %#v", module.Errors)
 	}
 }
+
+func writeFakeTerraform(t *testing.T, stateList string) string {
+	t.Helper()
+	return writeScript(t, fmt.Sprintf(`case "$1" in
+  state) printf '%%s' '%s' ;;
+esac
+exit 0
+`, stateList))
+}
+
+func TestModule_DestroyVerifiesEmptyState(t *testing.T) {
+	t.Run("empty state runs cleanup", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		stateFile := filepath.Join(tmpDir, "terraform.tfstate")
+		if err := os.WriteFile(stateFile, []byte("{}"), 0644); err != nil {
+			t.Fatalf("Failed to create state file: %v", err)
+		}
+
+		module := NewModule("clean", tmpDir)
+		module.Options.TerraformBinary = writeFakeTerraform(t, "")
+
+		if err := module.Destroy(testContext(t), t); err != nil {
+			t.Fatalf("Destroy() error = %v", err)
+		}
+		if len(module.Errors) != 0 || len(module.RemainingResources) != 0 {
+			t.Fatalf("expected no errors, got %v", module.Errors)
+		}
+		if _, err := os.Stat(stateFile); !os.IsNotExist(err) {
+			t.Errorf("state file should have been removed by cleanup")
+		}
+	})
+
+	t.Run("leftover resources keep state files", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		stateFile := filepath.Join(tmpDir, "terraform.tfstate")
+		if err := os.WriteFile(stateFile, []byte("{}"), 0644); err != nil {
+			t.Fatalf("Failed to create state file: %v", err)
+		}
+
+		module := NewModule("leaky", tmpDir)
+		module.Options.TerraformBinary = writeFakeTerraform(t, "azurerm_resource_group.rg\nmodule.vnet.azurerm_virtual_network.this\n")
+
+		err := module.Destroy(testContext(t), t)
+		if err == nil {
+			t.Fatalf("expected destroy verification error")
+		}
+		if !strings.Contains(err.Error(), "destroy verification") || !strings.Contains(err.Error(), "module.vnet.azurerm_virtual_network.this") {
+			t.Errorf("expected error to name remaining resources, got %v", err)
+		}
+		if len(module.RemainingResources) != 2 {
+			t.Errorf("expected 2 remaining resources, got %v", module.RemainingResources)
+		}
+		if _, err := os.Stat(stateFile); err != nil {
+			t.Errorf("state file should be kept when resources remain: %v", err)
+		}
+	})
+
+	t.Run("failed state list after failed apply runs cleanup", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		stateFile := filepath.Join(tmpDir, "terraform.tfstate")
+		if err := os.WriteFile(stateFile, []byte("{}"), 0644); err != nil {
+			t.Fatalf("Failed to create state file: %v", err)
+		}
+
+		module := NewModule("broken", tmpDir)
+		module.ApplyFailed = true
+		module.Options.TerraformBinary = writeScript(t, `[ "$1" = state ] && { echo 'Error: backend unavailable' >&2; exit 1; }
+exit 0
+`)
+
+		if err := module.Destroy(testContext(t), t); err == nil {
+			t.Fatalf("expected the state list error to be returned")
+		}
+		if len(module.Errors) != 0 {
+			t.Errorf("expected no recorded errors after a failed apply, got %v", module.Errors)
+		}
+		if _, err := os.Stat(stateFile); !os.IsNotExist(err) {
+			t.Errorf("state file should have been removed by cleanup")
+		}
+	})
+
+	t.Run("missing state file counts as empty", func(t *testing.T) {
+		module := NewModule("stateless", t.TempDir())
+		module.Options.TerraformBinary = writeScript(t, `[ "$1" = state ] && { echo 'No state file was found!' >&2; exit 1; }
+exit 0
+`)
+
+		if err := module.Destroy(testContext(t), t); err != nil {
+			t.Fatalf("Destroy() error = %v", err)
+		}
+		if len(module.Errors) != 0 || len(module.RemainingResources) != 0 {
+			t.Errorf("expected no errors, got %v", module.Errors)
+		}
+	})
+}
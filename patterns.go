@@ -0,0 +1,122 @@
+package validor
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// regexPatternPrefix marks an -example or -exception entry as a regular
+// expression that has to match the whole example name.
+const regexPatternPrefix = "re:"
+
+// matchName reports whether name matches pattern, which is an exact name, a
+// glob such as private-* or a regular expression prefixed with re:.
+func matchName(pattern, name string) (bool, error) {
+	if expr, ok := strings.CutPrefix(pattern, regexPatternPrefix); ok {
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return false, fmt.Errorf("invalid example pattern %q: %w", pattern, err)
+		}
+		return re.MatchString(name), nil
+	}
+	matched, err := path.Match(pattern, name)
+	if err != nil {
+		return false, fmt.Errorf("invalid example pattern %q: %w", pattern, err)
+	}
+	return matched, nil
+}
+
+func matchesAnyPattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := matchName(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePatterns returns the available names matched by patterns, in
+// pattern order and without duplicates. A pattern that matches nothing is an
+// error listing the available names.
+func resolvePatterns(patterns, available []string) ([]string, error) {
+	var resolved []string
+	for _, pattern := range patterns {
+		var matches int
+		for _, name := range available {
+			matched, err := matchName(pattern, name)
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				continue
+			}
+			matches++
+			if !slices.Contains(resolved, name) {
+				resolved = append(resolved, name)
+			}
+		}
+		if matches == 0 {
+			return nil, fmt.Errorf("no example matches %q, available examples: %s", pattern, strings.Join(available, ", "))
+		}
+	}
+	return resolved, nil
+}
+
+// listExamples returns the examples below examplesPath, named by their
+// slash-separated relative path. Any directory with terraform files is an
+// example; hidden directories and the fixture are skipped.
+func listExamples(examplesPath string) ([]string, error) {
+	if _, err := os.ReadDir(examplesPath); err != nil {
+		return nil, fmt.Errorf("failed to read examples directory: %w", err)
+	}
+
+	var names []string
+	err := filepath.WalkDir(examplesPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() || path == examplesPath {
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") || entry.Name() == FixtureDirName {
+			return filepath.SkipDir
+		}
+		if !hasTerraformFiles(path) {
+			return nil
+		}
+		rel, err := filepath.Rel(examplesPath, path)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(rel))
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read examples directory: %w", err)
+	}
+	return names, nil
+}
+
+func hasTerraformFiles(dir string) bool {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	return err == nil && len(files) > 0
+}
+
+// resolveExamples resolves -example patterns against the examples on disk
+// and checks that every -exception pattern matches an example.
+func resolveExamples(config *Config, patterns []string) ([]string, error) {
+	available, err := listExamples(getExamplesPath(config))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := resolvePatterns(config.ExceptionList, available); err != nil {
+		return nil, fmt.Errorf("invalid -exception: %w", err)
+	}
+	return resolvePatterns(patterns, available)
+}
@@ -0,0 +1,161 @@
+package validor
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMatchName(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+		wantErr bool
+	}{
+		{pattern: "default", name: "default", want: true},
+		{pattern: "default", name: "default-complete", want: false},
+		{pattern: "private-*", name: "private-endpoint", want: true},
+		{pattern: "private-*", name: "public", want: false},
+		{pattern: "vnet-?", name: "vnet-1", want: true},
+		{pattern: "re:private-(endpoint|link)", name: "private-link", want: true},
+		{pattern: "re:private", name: "private-link", want: false},
+		{pattern: "re:.*-complete", name: "vnet-complete", want: true},
+		{pattern: "re:(", wantErr: true},
+		{pattern: "[", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, err := matchName(tt.pattern, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("matchName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("matchName(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvePatterns(t *testing.T) {
+	available := []string{"default", "private-endpoint", "private-link", "complete"}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+		wantErr  string
+	}{
+		{name: "exact", patterns: []string{"complete", "default"}, want: []string{"complete", "default"}},
+		{name: "glob", patterns: []string{"private-*"}, want: []string{"private-endpoint", "private-link"}},
+		{name: "regex", patterns: []string{"re:(default|complete)"}, want: []string{"default", "complete"}},
+		{name: "duplicates removed", patterns: []string{"private-link", "private-*"}, want: []string{"private-link", "private-endpoint"}},
+		{name: "unknown name", patterns: []string{"defualt"}, wantErr: `no example matches "defualt", available examples: default, private-endpoint, private-link, complete`},
+		{name: "glob without match", patterns: []string{"public-*"}, wantErr: `no example matches "public-*"`},
+		{name: "invalid regex", patterns: []string{"re:("}, wantErr: "invalid example pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolvePatterns(tt.patterns, available)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolvePatterns() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolvePatterns() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("resolvePatterns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveExamples(t *testing.T) {
+	examplesPath := t.TempDir()
+	for _, name := range []string{"default", "private-endpoint", FixtureDirName} {
+		writeFile(t, filepath.Join(examplesPath, name, "main.tf"), "")
+	}
+
+	config := NewConfig(WithExamplesPath(examplesPath), WithException("private-*"))
+	names, err := resolveExamples(config, []string{"*"})
+	if err != nil {
+		t.Fatalf("resolveExamples() error = %v", err)
+	}
+	if !slices.Equal(names, []string{"default", "private-endpoint"}) {
+		t.Errorf("resolveExamples() = %v, the fixture should not be an example", names)
+	}
+
+	config = NewConfig(WithExamplesPath(examplesPath), WithException("public-*"))
+	if _, err := resolveExamples(config, []string{"default"}); err == nil || !strings.Contains(err.Error(), "-exception") {
+		t.Errorf("expected an -exception error, got %v", err)
+	}
+}
+
+func TestDiscoverModules_ExceptionPatterns(t *testing.T) {
+	examplesPath := t.TempDir()
+	for _, name := range []string{"default", "private-endpoint", "private-link"} {
+		writeFile(t, filepath.Join(examplesPath, name, "main.tf"), "")
+	}
+
+	manager := NewModuleManager(examplesPath)
+	manager.SetConfig(NewConfig(WithException("re:private-.*")))
+	modules, err := manager.DiscoverModules()
+	if err != nil {
+		t.Fatalf("DiscoverModules() error = %v", err)
+	}
+	if got := moduleNames(modules); got != "default" {
+		t.Errorf("DiscoverModules() = %s, want default", got)
+	}
+
+	manager.SetConfig(NewConfig(WithException("missing")))
+	if _, err := manager.DiscoverModules(); err == nil || !strings.Contains(err.Error(), "available examples: default, private-endpoint, private-link") {
+		t.Errorf("expected an error listing the available examples, got %v", err)
+	}
+}
+
+func TestListExamples_Recursive(t *testing.T) {
+	examplesPath := t.TempDir()
+	writeFile(t, filepath.Join(examplesPath, "default", "main.tf"), "")
+	writeFile(t, filepath.Join(examplesPath, "default", "modules", "helper", "main.tf"), "")
+	writeFile(t, filepath.Join(examplesPath, "network", "peering", "main.tf"), "")
+	writeFile(t, filepath.Join(examplesPath, "network", "vnet", "sub", "main.tf"), "")
+	writeFile(t, filepath.Join(examplesPath, "network", "README.md"), "")
+	writeFile(t, filepath.Join(examplesPath, "empty", "notes.txt"), "")
+	writeFile(t, filepath.Join(examplesPath, ".hidden", "main.tf"), "")
+	writeFile(t, filepath.Join(examplesPath, "default", ".terraform", "modules", "x", "main.tf"), "")
+	writeFile(t, filepath.Join(examplesPath, FixtureDirName, "main.tf"), "")
+
+	names, err := listExamples(examplesPath)
+	if err != nil {
+		t.Fatalf("listExamples() error = %v", err)
+	}
+	want := []string{"default", "network/peering", "network/vnet/sub"}
+	if !slices.Equal(names, want) {
+		t.Errorf("listExamples() = %v, want %v", names, want)
+	}
+
+	config := NewConfig(WithExamplesPath(examplesPath), WithException("network/vnet/*"))
+	names, err = resolveExamples(config, []string{"network/*"})
+	if err != nil {
+		t.Fatalf("resolveExamples() error = %v", err)
+	}
+	if !slices.Equal(names, []string{"network/peering"}) {
+		t.Errorf("resolveExamples() = %v, want [network/peering]", names)
+	}
+
+	manager := NewModuleManager(examplesPath)
+	manager.SetConfig(NewConfig(WithException("re:network/.*")))
+	modules, err := manager.DiscoverModules()
+	if err != nil {
+		t.Fatalf("DiscoverModules() error = %v", err)
+	}
+	if got := moduleNames(modules); got != "default" {
+		t.Errorf("DiscoverModules() = %s, want default", got)
+	}
+}
@@ -0,0 +1,260 @@
+package validor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+const planExitCodeChanges = 2
+
+type PlanSummary struct {
+	Add     int
+	Change  int
+	Destroy int
+}
+
+func (p *PlanSummary) String() string {
+	return fmt.Sprintf("%d to add, %d to change, %d to destroy", p.Add, p.Change, p.Destroy)
+}
+
+func (m *Module) Plan(ctx context.Context, t *testing.T) error {
+	t.Helper()
+
+	t.Logf("Planning Terraform module: %s", m.Name)
+
+	plan, err := m.runPlan(ctx, t, true)
+	if m.expectsFailure() && ctx.Err() == nil {
+		return m.checkExpectedFailure(t, "terraform plan", err)
+	}
+	if err != nil {
+		return m.recordError(t, "terraform plan", err)
+	}
+
+	m.PlanSummary = summarizePlan(plan)
+	t.Logf("Plan for module %s: %s", m.Name, m.PlanSummary)
+	return nil
+}
+
+// ParsedPlan is a terraform plan in the terraform-json representation, with
+// queries for hooks and checks.
+type ParsedPlan struct {
+	*tfjson.Plan
+}
+
+// PlanJSON runs init and plan for the module and returns the parsed plan.
+// Unlike Plan it does not record errors on the module.
+func (m *Module) PlanJSON(ctx context.Context, t *testing.T) (*ParsedPlan, error) {
+	t.Helper()
+
+	plan, err := m.runPlan(ctx, t, true)
+	if err != nil {
+		return nil, &ModuleError{ModuleName: m.Name, Operation: "terraform plan", Err: err}
+	}
+	return &ParsedPlan{Plan: &plan.RawPlan}, nil
+}
+
+// ResourcesByType returns the changes of the managed resources of
+// resourceType, such as azurerm_virtual_network. Data sources are left out.
+func (p *ParsedPlan) ResourcesByType(resourceType string) []*tfjson.ResourceChange {
+	var changes []*tfjson.ResourceChange
+	for _, change := range p.ResourceChanges {
+		if change != nil && change.Mode == tfjson.ManagedResourceMode && change.Type == resourceType {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// ActionsByAddress returns the planned actions of every resource by address.
+func (p *ParsedPlan) ActionsByAddress() map[string]tfjson.Actions {
+	actions := make(map[string]tfjson.Actions, len(p.ResourceChanges))
+	for _, change := range p.ResourceChanges {
+		if change != nil && change.Change != nil {
+			actions[change.Address] = change.Change.Actions
+		}
+	}
+	return actions
+}
+
+// AttributeValue returns the planned value of an attribute of the resource at
+// address. The path uses the syntax of output assertions, e.g. tags.env or
+// address_space[0]. Values known only after apply are an error.
+func (p *ParsedPlan) AttributeValue(address, path string) (any, error) {
+	var change *tfjson.ResourceChange
+	for _, candidate := range p.ResourceChanges {
+		if candidate != nil && candidate.Address == address {
+			change = candidate
+			break
+		}
+	}
+	if change == nil || change.Change == nil {
+		return nil, fmt.Errorf("resource %s is not in the plan", address)
+	}
+
+	if unknown, err := selectPath(change.Change.AfterUnknown, path, false); err == nil && unknown == true {
+		return nil, fmt.Errorf("%s of %s is known only after apply", path, address)
+	}
+	value, err := selectPath(change.Change.After, path, false)
+	if err != nil {
+		return nil, fmt.Errorf("%s of %s: %w", path, address, err)
+	}
+	return value, nil
+}
+
+func (m *Module) CheckIdempotency(ctx context.Context, t *testing.T) error {
+	t.Helper()
+
+	t.Logf("Checking idempotency of Terraform module: %s", m.Name)
+
+	plan, err := m.runPlan(ctx, t, false)
+	if err != nil {
+		return m.recordError(t, "idempotency", err)
+	}
+
+	m.PendingChanges = pendingChanges(plan)
+	if len(m.PendingChanges) == 0 {
+		return nil
+	}
+
+	return m.recordError(t, "idempotency",
+		fmt.Errorf("plan after apply is not empty, %d resource(s) would change", len(m.PendingChanges)))
+}
+
+func (m *Module) runPlan(ctx context.Context, t *testing.T, init bool) (*terraform.PlanStruct, error) {
+	t.Helper()
+
+	if m.planHook != nil {
+		return m.planHook(ctx, t, m)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	planFile, err := os.CreateTemp("", "validor-plan-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create plan file: %w", err)
+	}
+	planFile.Close()
+	defer os.Remove(planFile.Name())
+
+	options, err := m.Options.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone terraform options: %w", err)
+	}
+	options.PlanFilePath = planFile.Name()
+
+	if init {
+		if _, err := runTerraform(ctx, t, options, initArgs(options)...); err != nil {
+			return nil, err
+		}
+	}
+
+	// plan -detailed-exitcode exits with 2 when the plan contains changes
+	if _, err := runTerraform(ctx, t, options, planArgs(options)...); err != nil {
+		var cmdErr *TerraformCommandError
+		if !errors.As(err, &cmdErr) || cmdErr.ExitCode != planExitCodeChanges || ctx.Err() != nil {
+			return nil, err
+		}
+	}
+
+	output, err := runTerraform(ctx, t, options, "show", "-json", "-no-color", options.PlanFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return terraform.ParsePlanJSON(output)
+}
+
+func summarizePlan(plan *terraform.PlanStruct) *PlanSummary {
+	summary := &PlanSummary{}
+	if plan == nil {
+		return summary
+	}
+
+	for _, change := range plan.ResourceChangesMap {
+		if change == nil || change.Change == nil {
+			continue
+		}
+		actions := change.Change.Actions
+		switch {
+		case actions.Replace():
+			summary.Add++
+			summary.Destroy++
+		case actions.Create():
+			summary.Add++
+		case actions.Update():
+			summary.Change++
+		case actions.Delete():
+			summary.Destroy++
+		}
+	}
+	return summary
+}
+
+func pendingChanges(plan *terraform.PlanStruct) []string {
+	if plan == nil {
+		return nil
+	}
+
+	var changes []string
+	for address, change := range plan.ResourceChangesMap {
+		if change == nil || change.Change == nil {
+			continue
+		}
+		actions := change.Change.Actions
+		if actions.NoOp() || actions.Read() {
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s (%s)", address, actionLabel(actions)))
+	}
+	slices.Sort(changes)
+	return changes
+}
+
+func destructiveChanges(plan *terraform.PlanStruct) []string {
+	if plan == nil {
+		return nil
+	}
+
+	var changes []string
+	for address, change := range plan.ResourceChangesMap {
+		if change == nil || change.Change == nil {
+			continue
+		}
+		actions := change.Change.Actions
+		if actions.Delete() || actions.Replace() {
+			changes = append(changes, fmt.Sprintf("%s (%s)", address, actionLabel(actions)))
+		}
+	}
+	slices.Sort(changes)
+	return changes
+}
+
+func actionLabel(actions tfjson.Actions) string {
+	switch {
+	case actions.Replace():
+		return "replace"
+	case actions.Create():
+		return "create"
+	case actions.Update():
+		return "update"
+	case actions.Delete():
+		return "delete"
+	}
+
+	labels := make([]string, 0, len(actions))
+	for _, action := range actions {
+		labels = append(labels, string(action))
+	}
+	return strings.Join(labels, ", ")
+}
@@ -0,0 +1,253 @@
+package validor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func planWithChanges(changes map[string]tfjson.Actions) *terraform.PlanStruct {
+	plan := &terraform.PlanStruct{ResourceChangesMap: map[string]*tfjson.ResourceChange{}}
+	for address, actions := range changes {
+		plan.ResourceChangesMap[address] = &tfjson.ResourceChange{
+			Address: address,
+			Change:  &tfjson.Change{Actions: actions},
+		}
+	}
+	return plan
+}
+
+func TestPendingChanges(t *testing.T) {
+	tests := []struct {
+		name string
+		plan *terraform.PlanStruct
+		want []string
+	}{
+		{
+			name: "nil plan",
+			plan: nil,
+			want: nil,
+		},
+		{
+			name: "only no-op and read actions",
+			plan: planWithChanges(map[string]tfjson.Actions{
+				"azurerm_resource_group.rg":       {tfjson.ActionNoop},
+				"data.azurerm_client_config.curr": {tfjson.ActionRead},
+			}),
+			want: nil,
+		},
+		{
+			name: "mixed actions sorted by address",
+			plan: planWithChanges(map[string]tfjson.Actions{
+				"module.vnet.azurerm_virtual_network.this": {tfjson.ActionUpdate},
+				"azurerm_resource_group.rg":                {tfjson.ActionNoop},
+				"azurerm_subnet.sn":                        {tfjson.ActionDelete, tfjson.ActionCreate},
+				"azurerm_public_ip.pip":                    {tfjson.ActionCreate},
+			}),
+			want: []string{
+				"azurerm_public_ip.pip (create)",
+				"azurerm_subnet.sn (replace)",
+				"module.vnet.azurerm_virtual_network.this (update)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pendingChanges(tt.plan)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pendingChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModule_CheckIdempotency(t *testing.T) {
+	t.Run("empty plan passes", func(t *testing.T) {
+		module := NewModule("stable", t.TempDir())
+		module.planHook = func(ctx context.Context, tb *testing.T, m *Module) (*terraform.PlanStruct, error) {
+			return planWithChanges(map[string]tfjson.Actions{
+				"azurerm_resource_group.rg": {tfjson.ActionNoop},
+			}), nil
+		}
+
+		if err := module.CheckIdempotency(testContext(t), t); err != nil {
+			t.Fatalf("CheckIdempotency() error = %v", err)
+		}
+		if len(module.Errors) != 0 {
+			t.Fatalf("expected no errors, got %v", module.Errors)
+		}
+	})
+
+	t.Run("pending changes fail", func(t *testing.T) {
+		module := NewModule("drifting", t.TempDir())
+		module.planHook = func(ctx context.Context, tb *testing.T, m *Module) (*terraform.PlanStruct, error) {
+			return planWithChanges(map[string]tfjson.Actions{
+				"azurerm_virtual_network.vnet": {tfjson.ActionUpdate},
+			}), nil
+		}
+
+		mock := &testing.T{}
+		err := module.CheckIdempotency(testContext(t), mock)
+		if err == nil {
+			t.Fatalf("expected idempotency error")
+		}
+
+		var moduleErr *ModuleError
+		if !errors.As(err, &moduleErr) || moduleErr.Operation != "idempotency" {
+			t.Fatalf("expected ModuleError with idempotency operation, got %v", err)
+		}
+		if !reflect.DeepEqual(module.PendingChanges, []string{"azurerm_virtual_network.vnet (update)"}) {
+			t.Fatalf("unexpected pending changes: %v", module.PendingChanges)
+		}
+	})
+
+	t.Run("plan error is recorded", func(t *testing.T) {
+		module := NewModule("broken", t.TempDir())
+		module.planHook = func(ctx context.Context, tb *testing.T, m *Module) (*terraform.PlanStruct, error) {
+			return nil, fmt.Errorf("plan failed")
+		}
+
+		if err := module.CheckIdempotency(testContext(t), &testing.T{}); err == nil {
+			t.Fatalf("expected error when plan fails")
+		}
+		if len(module.Errors) != 1 {
+			t.Fatalf("expected 1 error recorded, got %d", len(module.Errors))
+		}
+	})
+}
+
+func TestSummarizePlan(t *testing.T) {
+	plan := planWithChanges(map[string]tfjson.Actions{
+		"azurerm_resource_group.rg":     {tfjson.ActionCreate},
+		"azurerm_virtual_network.vnet":  {tfjson.ActionCreate},
+		"azurerm_subnet.sn":             {tfjson.ActionUpdate},
+		"azurerm_public_ip.pip":         {tfjson.ActionCreate, tfjson.ActionDelete},
+		"azurerm_network_interface.nic": {tfjson.ActionDelete},
+		"data.azurerm_client_config.cc": {tfjson.ActionRead},
+		"azurerm_key_vault.kv":          {tfjson.ActionNoop},
+	})
+
+	got := summarizePlan(plan)
+	want := &PlanSummary{Add: 3, Change: 1, Destroy: 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("summarizePlan() = %+v, want %+v", got, want)
+	}
+	if got.String() != "3 to add, 1 to change, 2 to destroy" {
+		t.Errorf("PlanSummary.String() = %q", got.String())
+	}
+}
+
+func TestModule_Plan(t *testing.T) {
+	module := NewModule("example1", t.TempDir())
+	module.planHook = func(ctx context.Context, tb *testing.T, m *Module) (*terraform.PlanStruct, error) {
+		return planWithChanges(map[string]tfjson.Actions{
+			"azurerm_resource_group.rg": {tfjson.ActionCreate},
+		}), nil
+	}
+
+	if err := module.Plan(testContext(t), t); err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if module.PlanSummary == nil || module.PlanSummary.Add != 1 {
+		t.Fatalf("expected plan summary with 1 addition, got %+v", module.PlanSummary)
+	}
+}
+
+const testPlanJSON = `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {
+      "address": "azurerm_virtual_network.vnet",
+      "mode": "managed",
+      "type": "azurerm_virtual_network",
+      "name": "vnet",
+      "change": {
+        "actions": ["create"],
+        "after": {"name": "vnet-test", "address_space": ["10.0.0.0/16"], "tags": {"env": "test"}},
+        "after_unknown": {"id": true, "address_space": [false]}
+      }
+    },
+    {
+      "address": "azurerm_subnet.snet",
+      "mode": "managed",
+      "type": "azurerm_subnet",
+      "name": "snet",
+      "change": {"actions": ["delete", "create"], "after": {"name": "snet-a"}, "after_unknown": {}}
+    },
+    {
+      "address": "data.azurerm_virtual_network.existing",
+      "mode": "data",
+      "type": "azurerm_virtual_network",
+      "name": "existing",
+      "change": {"actions": ["read"], "after": {}, "after_unknown": {}}
+    }
+  ]
+}`
+
+func TestModule_PlanJSON(t *testing.T) {
+	module := NewModule("network", t.TempDir())
+	module.planHook = func(ctx context.Context, tb *testing.T, m *Module) (*terraform.PlanStruct, error) {
+		return terraform.ParsePlanJSON(testPlanJSON)
+	}
+
+	plan, err := module.PlanJSON(testContext(t), t)
+	if err != nil {
+		t.Fatalf("PlanJSON() error = %v", err)
+	}
+
+	if vnets := plan.ResourcesByType("azurerm_virtual_network"); len(vnets) != 1 || vnets[0].Address != "azurerm_virtual_network.vnet" {
+		t.Errorf("ResourcesByType() = %v, want only the managed virtual network", vnets)
+	}
+
+	actions := plan.ActionsByAddress()
+	if !actions["azurerm_subnet.snet"].Replace() || !actions["azurerm_virtual_network.vnet"].Create() {
+		t.Errorf("ActionsByAddress() = %v", actions)
+	}
+
+	tests := []struct {
+		address string
+		path    string
+		want    any
+		wantErr bool
+	}{
+		{"azurerm_virtual_network.vnet", "name", "vnet-test", false},
+		{"azurerm_virtual_network.vnet", "tags.env", "test", false},
+		{"azurerm_virtual_network.vnet", "address_space[0]", "10.0.0.0/16", false},
+		{"azurerm_virtual_network.vnet", "id", nil, true},
+		{"azurerm_virtual_network.vnet", "location", nil, true},
+		{"azurerm_virtual_network.missing", "name", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address+"/"+tt.path, func(t *testing.T) {
+			got, err := plan.AttributeValue(tt.address, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AttributeValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("AttributeValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModule_PlanJSONError(t *testing.T) {
+	module := NewModule("broken", t.TempDir())
+	module.planHook = func(ctx context.Context, tb *testing.T, m *Module) (*terraform.PlanStruct, error) {
+		return nil, fmt.Errorf("plan failed")
+	}
+
+	_, err := module.PlanJSON(testContext(t), t)
+	var moduleErr *ModuleError
+	if !errors.As(err, &moduleErr) || moduleErr.Operation != "terraform plan" {
+		t.Fatalf("expected a terraform plan ModuleError, got %v", err)
+	}
+	if len(module.Errors) != 0 {
+		t.Errorf("PlanJSON() recorded errors %v, want none", module.Errors)
+	}
+}
@@ -0,0 +1,211 @@
+package validor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// PolicyRule checks the plan of an example before it is applied.
+type PolicyRule interface {
+	// ID names the rule in violations and in the waived_rules of a manifest.
+	ID() string
+	Evaluate(plan *ParsedPlan) []PolicyViolation
+}
+
+type PolicyViolation struct {
+	RuleID  string
+	Address string
+	Message string
+}
+
+func (v PolicyViolation) Error() string {
+	return fmt.Sprintf("%s violates %s: %s", v.Address, v.RuleID, v.Message)
+}
+
+// DefaultPolicyRules returns the built-in rules that need no settings.
+func DefaultPolicyRules() []PolicyRule {
+	return []PolicyRule{RequireTags(), DenyPublicIPs()}
+}
+
+// RequireTags requires every azurerm resource that supports tags to carry
+// tags, and all of the given tag names when there are any.
+func RequireTags(names ...string) PolicyRule {
+	return requireTagsRule{names: names}
+}
+
+type requireTagsRule struct {
+	names []string
+}
+
+func (r requireTagsRule) ID() string { return "require-tags" }
+
+func (r requireTagsRule) Evaluate(plan *ParsedPlan) []PolicyViolation {
+	var violations []PolicyViolation
+	for _, change := range plannedResources(plan) {
+		after, ok := change.Change.After.(map[string]any)
+		if !ok || !strings.HasPrefix(change.Type, "azurerm_") || isUnknown(change, "tags") {
+			continue
+		}
+		value, supportsTags := after["tags"]
+		if !supportsTags {
+			continue
+		}
+		tags, _ := value.(map[string]any)
+		if len(tags) == 0 {
+			violations = append(violations, PolicyViolation{RuleID: r.ID(), Address: change.Address, Message: "resource has no tags"})
+			continue
+		}
+		var missing []string
+		for _, name := range r.names {
+			if _, ok := tags[name]; !ok {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			violations = append(violations, PolicyViolation{
+				RuleID:  r.ID(),
+				Address: change.Address,
+				Message: "missing tags " + strings.Join(missing, ", "),
+			})
+		}
+	}
+	return violations
+}
+
+// DenyPublicIPs rejects public IP addresses and prefixes. Examples that need
+// one waive the no-public-ip rule.
+func DenyPublicIPs() PolicyRule {
+	return denyPublicIPsRule{}
+}
+
+type denyPublicIPsRule struct{}
+
+func (r denyPublicIPsRule) ID() string { return "no-public-ip" }
+
+func (r denyPublicIPsRule) Evaluate(plan *ParsedPlan) []PolicyViolation {
+	var violations []PolicyViolation
+	for _, change := range plannedResources(plan) {
+		if change.Type == "azurerm_public_ip" || change.Type == "azurerm_public_ip_prefix" {
+			violations = append(violations, PolicyViolation{
+				RuleID:  r.ID(),
+				Address: change.Address,
+				Message: "public IP addresses are not allowed",
+			})
+		}
+	}
+	return violations
+}
+
+// AllowedLocations restricts the location of every resource to locations.
+// Names are compared without case and spaces, so West Europe is westeurope.
+func AllowedLocations(locations ...string) PolicyRule {
+	allowed := make([]string, len(locations))
+	for i, location := range locations {
+		allowed[i] = normalizeLocation(location)
+	}
+	return allowedLocationsRule{allowed: allowed}
+}
+
+type allowedLocationsRule struct {
+	allowed []string
+}
+
+func (r allowedLocationsRule) ID() string { return "allowed-locations" }
+
+func (r allowedLocationsRule) Evaluate(plan *ParsedPlan) []PolicyViolation {
+	var violations []PolicyViolation
+	for _, change := range plannedResources(plan) {
+		after, ok := change.Change.After.(map[string]any)
+		if !ok || isUnknown(change, "location") {
+			continue
+		}
+		location, ok := after["location"].(string)
+		if !ok || slices.Contains(r.allowed, normalizeLocation(location)) {
+			continue
+		}
+		violations = append(violations, PolicyViolation{
+			RuleID:  r.ID(),
+			Address: change.Address,
+			Message: fmt.Sprintf("location %s is not allowed", location),
+		})
+	}
+	return violations
+}
+
+func normalizeLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
+
+// plannedResources returns the managed resources the plan creates or updates.
+func plannedResources(plan *ParsedPlan) []*tfjson.ResourceChange {
+	var changes []*tfjson.ResourceChange
+	for _, change := range plan.ResourceChanges {
+		if change == nil || change.Change == nil || change.Mode != tfjson.ManagedResourceMode {
+			continue
+		}
+		if actions := change.Change.Actions; actions.Create() || actions.Update() || actions.Replace() {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+func isUnknown(change *tfjson.ResourceChange, attribute string) bool {
+	unknown, ok := change.Change.AfterUnknown.(map[string]any)
+	return ok && unknown[attribute] == true
+}
+
+// policyRules returns the rules configured for the run.
+func (c *Config) policyRules() []PolicyRule {
+	rules := slices.Clone(c.PolicyRules)
+	if c.Policy {
+		rules = append(rules, DefaultPolicyRules()...)
+	}
+	if len(c.AllowedLocations) > 0 {
+		rules = append(rules, AllowedLocations(c.AllowedLocations...))
+	}
+	return rules
+}
+
+// CheckPolicies plans the module and evaluates rules against the plan, except
+// the rules its manifest waives. Every violation is recorded as its own error.
+func (m *Module) CheckPolicies(ctx context.Context, t *testing.T, rules []PolicyRule) error {
+	t.Helper()
+
+	var waived []string
+	if m.Manifest != nil {
+		waived = m.Manifest.WaivedRules
+	}
+	var active []PolicyRule
+	for _, rule := range rules {
+		if slices.Contains(waived, rule.ID()) {
+			t.Logf("Policy rule %s is waived for module %s", rule.ID(), m.Name)
+			continue
+		}
+		active = append(active, rule)
+	}
+	if len(active) == 0 {
+		return nil
+	}
+
+	t.Logf("Checking %d policy rule(s) for module %s", len(active), m.Name)
+
+	plan, err := m.runPlan(ctx, t, true)
+	if err != nil {
+		return m.recordError(t, "policy check", err)
+	}
+
+	parsed := &ParsedPlan{Plan: &plan.RawPlan}
+	var failed error
+	for _, rule := range active {
+		for _, violation := range rule.Evaluate(parsed) {
+			failed = m.recordError(t, "policy check", violation)
+		}
+	}
+	return failed
+}
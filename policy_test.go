@@ -0,0 +1,162 @@
+package validor
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+const testPolicyPlanJSON = `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {
+      "address": "azurerm_resource_group.rg",
+      "mode": "managed",
+      "type": "azurerm_resource_group",
+      "name": "rg",
+      "change": {"actions": ["create"], "after": {"location": "westeurope", "tags": {"env": "test", "owner": "platform"}}, "after_unknown": {}}
+    },
+    {
+      "address": "azurerm_virtual_network.vnet",
+      "mode": "managed",
+      "type": "azurerm_virtual_network",
+      "name": "vnet",
+      "change": {"actions": ["create"], "after": {"location": "East US", "tags": {"env": "test"}}, "after_unknown": {}}
+    },
+    {
+      "address": "azurerm_public_ip.pip",
+      "mode": "managed",
+      "type": "azurerm_public_ip",
+      "name": "pip",
+      "change": {"actions": ["create"], "after": {"location": "West Europe", "tags": null}, "after_unknown": {}}
+    },
+    {
+      "address": "azurerm_subnet.snet",
+      "mode": "managed",
+      "type": "azurerm_subnet",
+      "name": "snet",
+      "change": {"actions": ["create"], "after": {"name": "snet"}, "after_unknown": {}}
+    },
+    {
+      "address": "azurerm_storage_account.old",
+      "mode": "managed",
+      "type": "azurerm_storage_account",
+      "name": "old",
+      "change": {"actions": ["delete"], "before": {"location": "northeurope"}, "after": null, "after_unknown": {}}
+    }
+  ]
+}`
+
+func testPolicyPlan(t *testing.T) *ParsedPlan {
+	t.Helper()
+	plan, err := terraform.ParsePlanJSON(testPolicyPlanJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &ParsedPlan{Plan: &plan.RawPlan}
+}
+
+func TestPolicyRules(t *testing.T) {
+	plan := testPolicyPlan(t)
+
+	tests := []struct {
+		name string
+		rule PolicyRule
+		want []PolicyViolation
+	}{
+		{
+			name: "require tags",
+			rule: RequireTags(),
+			want: []PolicyViolation{
+				{RuleID: "require-tags", Address: "azurerm_public_ip.pip", Message: "resource has no tags"},
+			},
+		},
+		{
+			name: "require named tags",
+			rule: RequireTags("env", "owner"),
+			want: []PolicyViolation{
+				{RuleID: "require-tags", Address: "azurerm_virtual_network.vnet", Message: "missing tags owner"},
+				{RuleID: "require-tags", Address: "azurerm_public_ip.pip", Message: "resource has no tags"},
+			},
+		},
+		{
+			name: "deny public ips",
+			rule: DenyPublicIPs(),
+			want: []PolicyViolation{
+				{RuleID: "no-public-ip", Address: "azurerm_public_ip.pip", Message: "public IP addresses are not allowed"},
+			},
+		},
+		{
+			name: "allowed locations",
+			rule: AllowedLocations("West Europe"),
+			want: []PolicyViolation{
+				{RuleID: "allowed-locations", Address: "azurerm_virtual_network.vnet", Message: "location East US is not allowed"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Evaluate(plan); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModule_CheckPolicies(t *testing.T) {
+	newModule := func(t *testing.T, waived ...string) *Module {
+		module := NewModule("network", t.TempDir())
+		module.Manifest = &Manifest{WaivedRules: waived}
+		module.planHook = func(ctx context.Context, tb *testing.T, m *Module) (*terraform.PlanStruct, error) {
+			return terraform.ParsePlanJSON(testPolicyPlanJSON)
+		}
+		return module
+	}
+
+	t.Run("violations are recorded", func(t *testing.T) {
+		module := newModule(t)
+		err := module.CheckPolicies(testContext(t), t, DefaultPolicyRules())
+		var violation PolicyViolation
+		if !errors.As(err, &violation) {
+			t.Fatalf("expected a policy violation, got %v", err)
+		}
+		if len(module.Errors) != 2 {
+			t.Fatalf("expected 2 errors, got %v", module.Errors)
+		}
+		want := "policy check failed for module network: azurerm_public_ip.pip violates require-tags: resource has no tags"
+		if module.Errors[0].Error() != want {
+			t.Errorf("Errors[0] = %q, want %q", module.Errors[0], want)
+		}
+	})
+
+	t.Run("waived rules are not checked", func(t *testing.T) {
+		module := newModule(t, "require-tags", "no-public-ip")
+		module.planHook = nil
+		if err := module.CheckPolicies(testContext(t), t, DefaultPolicyRules()); err != nil {
+			t.Fatalf("CheckPolicies() error = %v", err)
+		}
+		if len(module.Errors) != 0 {
+			t.Errorf("expected no errors, got %v", module.Errors)
+		}
+	})
+}
+
+func TestConfig_PolicyRules(t *testing.T) {
+	config := NewConfig(WithPolicy(true), WithAllowedLocations("westeurope"), WithPolicyRules(RequireTags("owner")))
+
+	var ids []string
+	for _, rule := range config.policyRules() {
+		ids = append(ids, rule.ID())
+	}
+	want := []string{"require-tags", "require-tags", "no-public-ip", "allowed-locations"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("policyRules() = %v, want %v", ids, want)
+	}
+	if rules := NewConfig().policyRules(); len(rules) != 0 {
+		t.Errorf("policyRules() = %v, want none by default", rules)
+	}
+}
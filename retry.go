@@ -0,0 +1,147 @@
+package validor
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultMaxRetries         = 3
+	defaultTimeBetweenRetries = 5 * time.Second
+)
+
+type RetryPolicy struct {
+	RetryableErrors    map[string]string `yaml:"retryable_errors"`
+	MaxRetries         int               `yaml:"max_retries"`
+	TimeBetweenRetries time.Duration     `yaml:"time_between_retries"`
+}
+
+// retryPolicyFile is a retry policy as written in YAML. An unset max_retries
+// keeps the default, while max_retries: 0 turns retries off.
+type retryPolicyFile struct {
+	RetryableErrors    map[string]string `yaml:"retryable_errors"`
+	MaxRetries         *int              `yaml:"max_retries"`
+	TimeBetweenRetries time.Duration     `yaml:"time_between_retries"`
+}
+
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		RetryableErrors:    maps.Clone(terraform.DefaultRetryableTerraformErrors),
+		MaxRetries:         defaultMaxRetries,
+		TimeBetweenRetries: defaultTimeBetweenRetries,
+	}
+}
+
+func LoadRetryPolicy(path string) (*RetryPolicy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retry policy %s: %w", path, err)
+	}
+
+	var fromFile retryPolicyFile
+	if err := yaml.Unmarshal(content, &fromFile); err != nil {
+		return nil, fmt.Errorf("failed to parse retry policy %s: %w", path, err)
+	}
+
+	policy := DefaultRetryPolicy()
+	maps.Copy(policy.RetryableErrors, fromFile.RetryableErrors)
+	if fromFile.MaxRetries != nil {
+		policy.MaxRetries = *fromFile.MaxRetries
+	}
+	if fromFile.TimeBetweenRetries != 0 {
+		policy.TimeBetweenRetries = fromFile.TimeBetweenRetries
+	}
+
+	if _, err := policy.compile(); err != nil {
+		return nil, fmt.Errorf("invalid retry policy %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+type retryableError struct {
+	pattern *regexp.Regexp
+	reason  string
+}
+
+// compile returns the retryable errors in a fixed order, custom patterns
+// before the terratest defaults and each sorted, so that output matching
+// several patterns always logs the same reason.
+func (p *RetryPolicy) compile() ([]retryableError, error) {
+	var custom, defaults []string
+	for _, pattern := range slices.Sorted(maps.Keys(p.RetryableErrors)) {
+		if _, ok := terraform.DefaultRetryableTerraformErrors[pattern]; ok {
+			defaults = append(defaults, pattern)
+		} else {
+			custom = append(custom, pattern)
+		}
+	}
+
+	compiled := make([]retryableError, 0, len(p.RetryableErrors))
+	for _, pattern := range append(custom, defaults...) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile retryable error %q: %w", pattern, err)
+		}
+		compiled = append(compiled, retryableError{pattern: re, reason: p.RetryableErrors[pattern]})
+	}
+	return compiled, nil
+}
+
+func (p *RetryPolicy) do(ctx context.Context, t testLogger, description string, action func() (string, error)) (string, error) {
+	t.Helper()
+
+	retryableErrors, err := p.compile()
+	if err != nil {
+		return "", err
+	}
+
+	for attempt := 0; ; attempt++ {
+		output, err := action()
+		if err == nil || ctx.Err() != nil {
+			return output, err
+		}
+
+		reason, retryable := matchRetryableError(retryableErrors, output, err)
+		if !retryable {
+			return output, err
+		}
+		if attempt >= p.MaxRetries {
+			return output, fmt.Errorf("%s failed after %d retries: %w", description, p.MaxRetries, err)
+		}
+
+		t.Logf("Retrying %s (attempt %d of %d) in %s: %s", description, attempt+1, p.MaxRetries, p.TimeBetweenRetries, reason)
+
+		select {
+		case <-ctx.Done():
+			return output, ctx.Err()
+		case <-time.After(p.TimeBetweenRetries):
+		}
+	}
+}
+
+func matchRetryableError(retryableErrors []retryableError, output string, err error) (string, bool) {
+	for _, retryable := range retryableErrors {
+		if retryable.pattern.MatchString(output) || retryable.pattern.MatchString(err.Error()) {
+			return retryable.reason, true
+		}
+	}
+	return "", false
+}
+
+func resolveRetryPolicy(config *Config) (*RetryPolicy, error) {
+	if config.RetryPolicy != nil {
+		return config.RetryPolicy, nil
+	}
+	if config.RetryPolicyFile != "" {
+		return LoadRetryPolicy(config.RetryPolicyFile)
+	}
+	return DefaultRetryPolicy(), nil
+}
@@ -0,0 +1,195 @@
+package validor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadRetryPolicy(t *testing.T) {
+	t.Run("merges file onto defaults", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "retry.yaml")
+		content := `
+max_retries: 6
+time_between_retries: 30s
+retryable_errors:
+  ".*AnotherOperationInProgress.*": "Azure operation already in progress"
+  ".*StatusCode=429.*": "Azure API throttling"
+`
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write retry policy: %v", err)
+		}
+
+		policy, err := LoadRetryPolicy(path)
+		if err != nil {
+			t.Fatalf("LoadRetryPolicy() error = %v", err)
+		}
+		if policy.MaxRetries != 6 {
+			t.Errorf("MaxRetries = %d, want 6", policy.MaxRetries)
+		}
+		if policy.TimeBetweenRetries != 30*time.Second {
+			t.Errorf("TimeBetweenRetries = %s, want 30s", policy.TimeBetweenRetries)
+		}
+		if policy.RetryableErrors[".*StatusCode=429.*"] != "Azure API throttling" {
+			t.Errorf("expected custom retryable error to be loaded, got %v", policy.RetryableErrors)
+		}
+		if len(policy.RetryableErrors) <= 2 {
+			t.Errorf("expected default retryable errors to be kept, got %d entries", len(policy.RetryableErrors))
+		}
+	})
+
+	t.Run("keeps defaults for unset fields", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "retry.yaml")
+		if err := os.WriteFile(path, []byte("retryable_errors: {}\n"), 0644); err != nil {
+			t.Fatalf("Failed to write retry policy: %v", err)
+		}
+
+		policy, err := LoadRetryPolicy(path)
+		if err != nil {
+			t.Fatalf("LoadRetryPolicy() error = %v", err)
+		}
+		if policy.MaxRetries != defaultMaxRetries || policy.TimeBetweenRetries != defaultTimeBetweenRetries {
+			t.Errorf("expected default retry settings, got %+v", policy)
+		}
+	})
+
+	t.Run("zero max retries turns retries off", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "retry.yaml")
+		if err := os.WriteFile(path, []byte("max_retries: 0\n"), 0644); err != nil {
+			t.Fatalf("Failed to write retry policy: %v", err)
+		}
+
+		policy, err := LoadRetryPolicy(path)
+		if err != nil {
+			t.Fatalf("LoadRetryPolicy() error = %v", err)
+		}
+		if policy.MaxRetries != 0 {
+			t.Errorf("MaxRetries = %d, want 0", policy.MaxRetries)
+		}
+	})
+
+	t.Run("rejects invalid regex", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "retry.yaml")
+		if err := os.WriteFile(path, []byte("retryable_errors:\n  \"[\": \"broken\"\n"), 0644); err != nil {
+			t.Fatalf("Failed to write retry policy: %v", err)
+		}
+
+		if _, err := LoadRetryPolicy(path); err == nil {
+			t.Error("expected error for invalid regex")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := LoadRetryPolicy("/non/existent/retry.yaml"); err == nil {
+			t.Error("expected error for missing file")
+		}
+	})
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	policy := &RetryPolicy{
+		RetryableErrors: map[string]string{".*RetryableError.*": "Azure transient error"},
+		MaxRetries:      2,
+	}
+
+	t.Run("retries matching errors and logs reason", func(t *testing.T) {
+		mock := &mockTB{}
+		attempts := 0
+		output, err := policy.do(context.Background(), mock, "terraform apply", func() (string, error) {
+			attempts++
+			if attempts < 2 {
+				return "Error: RetryableError: try again", fmt.Errorf("exit status 1")
+			}
+			return "Apply complete!", nil
+		})
+		if err != nil {
+			t.Fatalf("do() error = %v", err)
+		}
+		if output != "Apply complete!" || attempts != 2 {
+			t.Errorf("expected success on second attempt, got output %q after %d attempts", output, attempts)
+		}
+		joined := strings.Join(mock.logs, "\n")
+		if !strings.Contains(joined, "attempt 1 of 2") || !strings.Contains(joined, "Azure transient error") {
+			t.Errorf("expected retry attempt and reason to be logged, got %q", joined)
+		}
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		attempts := 0
+		_, err := policy.do(context.Background(), &mockTB{}, "terraform apply", func() (string, error) {
+			attempts++
+			return "Error: invalid value", fmt.Errorf("exit status 1")
+		})
+		if err == nil || attempts != 1 {
+			t.Errorf("expected a single failed attempt, got %d attempts and error %v", attempts, err)
+		}
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		attempts := 0
+		sentinel := errors.New("RetryableError")
+		_, err := policy.do(context.Background(), &mockTB{}, "terraform destroy", func() (string, error) {
+			attempts++
+			return "", sentinel
+		})
+		if !errors.Is(err, sentinel) || attempts != 3 {
+			t.Errorf("expected 3 attempts ending in the last error, got %d attempts and error %v", attempts, err)
+		}
+	})
+
+	t.Run("stops waiting when context is cancelled", func(t *testing.T) {
+		slow := &RetryPolicy{
+			RetryableErrors:    policy.RetryableErrors,
+			MaxRetries:         5,
+			TimeBetweenRetries: time.Hour,
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := slow.do(ctx, &mockTB{}, "terraform apply", func() (string, error) {
+			return "RetryableError", fmt.Errorf("exit status 1")
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+}
+
+func TestResolveRetryPolicy(t *testing.T) {
+	custom := &RetryPolicy{MaxRetries: 9}
+	policy, err := resolveRetryPolicy(NewConfig(WithRetryPolicy(custom)))
+	if err != nil || policy != custom {
+		t.Errorf("expected explicit retry policy to be used, got %+v, %v", policy, err)
+	}
+
+	policy, err = resolveRetryPolicy(NewConfig())
+	if err != nil || policy.MaxRetries != defaultMaxRetries {
+		t.Errorf("expected default retry policy, got %+v, %v", policy, err)
+	}
+
+	if _, err := resolveRetryPolicy(NewConfig(WithRetryPolicyFile("/non/existent/retry.yaml"))); err == nil {
+		t.Error("expected error for missing retry policy file")
+	}
+}
+
+func TestMatchRetryableError_PrefersCustomPatterns(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	policy.RetryableErrors[".*connection reset.*"] = "Azure connection reset"
+	policy.RetryableErrors[".*reset by peer.*"] = "Azure peer reset"
+
+	retryableErrors, err := policy.compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 10 {
+		reason, ok := matchRetryableError(retryableErrors, "read: connection reset by peer", errors.New("exit status 1"))
+		if !ok || reason != "Azure connection reset" {
+			t.Fatalf("matchRetryableError() = %q, %v, want the first custom pattern", reason, ok)
+		}
+	}
+}
@@ -1,9 +1,293 @@
 package validor
 
 import (
+	"context"
+	"fmt"
+	"sync"
 	"testing"
 )
 
 func RunTests(t *testing.T, modules []*Module, parallel bool, config *Config) {
 	runModuleTests(t, modules, parallel, config, nil, "registry")
 }
+
+type moduleRunner struct {
+	config           *Config
+	sourceType       string
+	upgradeInfo      ModuleInfo
+	upgradeConverter SourceConverter
+	applySlots       semaphore
+	destroySlots     semaphore
+	graph            *dependencyGraph
+	journal          *Journal
+	toolchain        Toolchain
+
+	mu       sync.Mutex
+	deferred []*Module
+}
+
+func newModuleRunner(config *Config, sourceType string) *moduleRunner {
+	return &moduleRunner{
+		config:       config,
+		sourceType:   sourceType,
+		applySlots:   newSemaphore(config.MaxParallel),
+		destroySlots: newSemaphore(config.MaxParallelDestroy),
+	}
+}
+
+func (r *moduleRunner) run(ctx context.Context, t *testing.T, module *Module) {
+	outcome := "failed"
+	defer func() { r.graph.markApplied(module.Name, outcome) }()
+
+	if reason := module.skipReason(); reason != "" {
+		module.SkipReason = reason
+		outcome = "was skipped"
+		return
+	}
+
+	blockedReason, err := r.graph.waitForDependencies(ctx, module)
+	if interrupted(ctx) {
+		module.Interrupted = true
+		outcome = "was interrupted"
+		return
+	}
+	if err != nil {
+		module.recordError(t, "scheduling", err)
+		t.Fail()
+		return
+	}
+	if blockedReason != "" {
+		module.BlockedReason = blockedReason
+		outcome = "is blocked"
+		return
+	}
+
+	if err := r.applySlots.acquire(ctx, t, "apply"); err != nil {
+		if interrupted(ctx) {
+			module.Interrupted = true
+			outcome = "was interrupted"
+			return
+		}
+		module.recordError(t, "scheduling", err)
+		t.Fail()
+		return
+	}
+	release := sync.OnceFunc(r.applySlots.release)
+	defer release()
+
+	exampleCtx, cancel := exampleContext(ctx, module.timeoutConfig(r.config), t.Deadline)
+	defer cancel()
+
+	if r.config.Validate || r.config.PlanOnly {
+		r.runStatic(exampleCtx, t, module)
+		module.Interrupted = interrupted(ctx)
+		outcome = applyOutcome(t.Failed(), module.Interrupted)
+		return
+	}
+
+	r.apply(exampleCtx, t, module)
+	module.Interrupted = interrupted(ctx)
+	outcome = applyOutcome(module.ApplyFailed, module.Interrupted)
+	release()
+
+	if r.config.SkipDestroy {
+		return
+	}
+	if r.graph.hasDependents(module.Name) {
+		t.Logf("Deferring destroy of module %s until the examples depending on it are destroyed", module.Name)
+		r.mu.Lock()
+		r.deferred = append(r.deferred, module)
+		r.mu.Unlock()
+		return
+	}
+	r.destroyExample(ctx, t, module)
+}
+
+func applyOutcome(failed, interrupted bool) string {
+	switch {
+	case interrupted:
+		return "was interrupted"
+	case failed:
+		return "failed"
+	default:
+		return ""
+	}
+}
+
+// destroyDeferred destroys the examples other examples depend on, once all
+// example subtests have finished, in reverse dependency order.
+func (r *moduleRunner) destroyDeferred(ctx context.Context, t *testing.T) {
+	r.mu.Lock()
+	deferred := r.deferred
+	r.mu.Unlock()
+
+	for _, module := range r.graph.destroyOrder(deferred) {
+		t.Logf("Destroying module %s after its dependents", module.Name)
+		r.destroyExample(ctx, t, module)
+	}
+}
+
+// destroyExample runs the pre-destroy hooks of an applied example before
+// destroying it.
+func (r *moduleRunner) destroyExample(ctx context.Context, t *testing.T, module *Module) {
+	if !module.ApplyFailed {
+		if err := runHooks(ctx, t, r.config, module, hookPreDestroy); err != nil {
+			t.Fail()
+		}
+	}
+	r.destroy(ctx, t, module)
+}
+
+func (r *moduleRunner) runStatic(ctx context.Context, t *testing.T, module *Module) {
+	if r.config.Validate {
+		if err := module.Validate(ctx, t); err != nil {
+			t.Fail()
+		}
+	}
+	if r.config.PlanOnly && !t.Failed() {
+		if err := module.Plan(ctx, t); err != nil {
+			t.Fail()
+		}
+	}
+	if err := module.Cleanup(context.WithoutCancel(ctx), t); err != nil {
+		module.recordError(t, "cleanup", err)
+	}
+}
+
+func (r *moduleRunner) apply(ctx context.Context, t *testing.T, module *Module) {
+	if err := runHooks(ctx, t, r.config, module, hookPreApply); err != nil {
+		module.ApplyFailed = true
+		t.Fail()
+		return
+	}
+	if rules := r.config.policyRules(); len(rules) > 0 && !module.expectsFailure() {
+		if err := module.CheckPolicies(ctx, t, rules); err != nil {
+			module.ApplyFailed = true
+			t.Fail()
+			return
+		}
+	}
+
+	r.record(t, module, JournalApplying)
+	applyCtx, cancel := applyContext(ctx, module.timeoutConfig(r.config), t.Deadline)
+	err := module.Apply(applyCtx, t)
+	cancel()
+	if err != nil {
+		t.Fail()
+		return
+	}
+	if module.FailedAsExpected {
+		return
+	}
+	r.record(t, module, JournalApplied)
+
+	t.Logf("✓ Module %s applied successfully with %s source", module.Name, r.sourceType)
+
+	if err := module.AssertOutputs(ctx, t); err != nil {
+		t.Fail()
+	}
+	if err := runHooks(ctx, t, r.config, module, hookPostApply); err != nil {
+		t.Fail()
+	}
+
+	if r.config.Idempotency {
+		if err := module.CheckIdempotency(ctx, t); err != nil {
+			t.Fail()
+		}
+	}
+
+	if r.config.Upgrade {
+		filesToRestore, err := module.Upgrade(ctx, t, r.upgradeConverter, r.upgradeInfo, r.config.UpgradeApply)
+		if !r.config.Isolate {
+			t.Cleanup(func() {
+				if err := restoreFiles(filesToRestore); err != nil {
+					t.Logf("Warning: Failed to restore registry source for module %s: %v", module.Name, err)
+				}
+			})
+		}
+		if err != nil {
+			t.Fail()
+		}
+	}
+}
+
+func (r *moduleRunner) destroy(ctx context.Context, t *testing.T, module *Module) {
+	destroyCtx, cancel := destroyContext(ctx, module.timeoutConfig(r.config), t.Deadline)
+	defer cancel()
+
+	if module.FailedAsExpected {
+		if resources, err := module.stateResources(destroyCtx, t); err == nil && len(resources) == 0 {
+			t.Logf("Skipping destroy of module %s as its expected failure created no resources", module.Name)
+			if err := module.Cleanup(destroyCtx, t); err != nil {
+				module.recordError(t, "cleanup", err)
+			}
+			r.record(t, module, JournalDestroyed)
+			return
+		}
+	}
+
+	if err := r.destroySlots.acquire(destroyCtx, t, "destroy"); err != nil {
+		module.recordError(t, "scheduling", err)
+		t.Fail()
+		return
+	}
+	defer r.destroySlots.release()
+
+	r.record(t, module, JournalDestroying)
+	err := module.Destroy(destroyCtx, t)
+	if err != nil && !module.ApplyFailed {
+		t.Logf("Cleanup failed for module %s: %v", module.Name, err)
+	}
+	if len(module.RemainingResources) > 0 {
+		t.Fail()
+		return
+	}
+	if err == nil {
+		r.record(t, module, JournalDestroyed)
+	}
+}
+
+func (r *moduleRunner) record(t *testing.T, module *Module, phase string) {
+	if err := r.journal.Record(module, phase); err != nil {
+		t.Logf("Warning: Failed to record %s of module %s in the journal: %v", phase, module.Name, err)
+	}
+}
+
+// semaphore bounds how many examples run a phase at once. A nil semaphore
+// places no limit.
+type semaphore chan struct{}
+
+func newSemaphore(limit int) semaphore {
+	if limit <= 0 {
+		return nil
+	}
+	return make(semaphore, limit)
+}
+
+func (s semaphore) acquire(ctx context.Context, t testLogger, phase string) error {
+	t.Helper()
+
+	if s == nil {
+		return nil
+	}
+
+	select {
+	case s <- struct{}{}:
+		return nil
+	default:
+	}
+
+	t.Logf("Waiting for a free %s slot (limit %d)", phase, cap(s))
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for a free %s slot: %w", phase, ctx.Err())
+	}
+}
+
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
@@ -3,8 +3,14 @@ package validor
 import (
 	"context"
 	"fmt"
+	"os"
+	"os/exec"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
 )
 
 type mockTB struct {
@@ -121,3 +127,297 @@ func TestPrintModuleSummary_CapturesOutput(t *testing.T) {
 		t.Fatalf("expected failure count in summary, got %q", joined)
 	}
 }
+
+func TestRunModuleTests_IdempotencyCheck(t *testing.T) {
+	module := NewModule("mod1", t.TempDir())
+	var planned bool
+
+	module.applyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+		return nil
+	}
+	module.destroyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+		return nil
+	}
+	module.planHook = func(ctx context.Context, tb *testing.T, m *Module) (*terraform.PlanStruct, error) {
+		planned = true
+		return &terraform.PlanStruct{}, nil
+	}
+
+	config := &Config{Idempotency: true}
+	runModuleTests(t, []*Module{module}, false, config, nil, "local")
+
+	if !planned {
+		t.Fatalf("expected a plan to run after apply when Idempotency is enabled")
+	}
+}
+
+func TestRunModuleTests_PlanOnly(t *testing.T) {
+	module := NewModule("mod1", t.TempDir())
+	var applyCalled, destroyCalled bool
+
+	module.applyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+		applyCalled = true
+		return nil
+	}
+	module.destroyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+		destroyCalled = true
+		return nil
+	}
+	module.planHook = func(ctx context.Context, tb *testing.T, m *Module) (*terraform.PlanStruct, error) {
+		return &terraform.PlanStruct{}, nil
+	}
+
+	config := &Config{PlanOnly: true}
+	runModuleTests(t, []*Module{module}, false, config, nil, "local")
+
+	if applyCalled || destroyCalled {
+		t.Fatalf("apply and destroy should not be called in plan-only mode")
+	}
+	if module.PlanSummary == nil {
+		t.Fatalf("expected plan summary to be recorded on the module")
+	}
+}
+
+func TestPrintModuleSummary_PlanCounts(t *testing.T) {
+	mock := &mockTB{}
+	module := NewModule("planned", t.TempDir())
+	module.PlanSummary = &PlanSummary{Add: 2, Change: 1}
+
+	PrintModuleSummary(mock, []*Module{module})
+
+	joined := strings.Join(mock.logs, "\n")
+	if !strings.Contains(joined, "planned plan: 2 to add, 1 to change, 0 to destroy") {
+		t.Fatalf("expected plan counts in summary, got %q", joined)
+	}
+	if !strings.Contains(joined, "All 1 modules planned successfully") {
+		t.Fatalf("expected plan-only success message, got %q", joined)
+	}
+}
+
+func TestRunModuleTests_MaxParallel(t *testing.T) {
+	var mu sync.Mutex
+	var applying, maxApplying, destroying, maxDestroying int
+
+	track := func(counter, peak *int, delta int) {
+		mu.Lock()
+		defer mu.Unlock()
+		*counter += delta
+		*peak = max(*peak, *counter)
+	}
+
+	var modules []*Module
+	for i := range 5 {
+		module := NewModule(fmt.Sprintf("mod%d", i), t.TempDir())
+		module.applyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+			track(&applying, &maxApplying, 1)
+			time.Sleep(20 * time.Millisecond)
+			track(&applying, &maxApplying, -1)
+			return nil
+		}
+		module.destroyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+			track(&destroying, &maxDestroying, 1)
+			time.Sleep(20 * time.Millisecond)
+			track(&destroying, &maxDestroying, -1)
+			return nil
+		}
+		modules = append(modules, module)
+	}
+
+	config := NewConfig(WithMaxParallel(2), WithMaxParallelDestroy(1))
+	t.Run("examples", func(t *testing.T) {
+		runModuleTests(t, modules, true, config, nil, "registry")
+	})
+
+	if maxApplying > 2 {
+		t.Errorf("expected at most 2 concurrent applies, got %d", maxApplying)
+	}
+	if maxDestroying > 1 {
+		t.Errorf("expected at most 1 concurrent destroy, got %d", maxDestroying)
+	}
+}
+
+func TestSemaphore_AcquireRespectsContext(t *testing.T) {
+	slots := newSemaphore(1)
+	if err := slots.acquire(context.Background(), &mockTB{}, "apply"); err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := slots.acquire(ctx, &mockTB{}, "apply"); err == nil {
+		t.Fatal("expected acquire to fail when the context is cancelled")
+	}
+
+	slots.release()
+	if err := newSemaphore(0).acquire(ctx, &mockTB{}, "apply"); err != nil {
+		t.Errorf("unbounded semaphore should never block, got %v", err)
+	}
+}
+
+func TestRunModuleTests_DependencyOrder(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	var modules []*Module
+	for _, module := range []*Module{
+		moduleWithDeps("app", "vnet"),
+		moduleWithDeps("vnet"),
+		moduleWithDeps("orphan", "excluded"),
+		moduleWithDeps("excluded"),
+	} {
+		module.Path = t.TempDir()
+		module.applyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+			time.Sleep(10 * time.Millisecond)
+			record("apply " + m.Name)
+			return nil
+		}
+		module.destroyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+			record("destroy " + m.Name)
+			return nil
+		}
+		modules = append(modules, module)
+	}
+
+	config := NewConfig(WithException("excluded"))
+	t.Run("examples", func(t *testing.T) {
+		runModuleTests(t, modules, true, config, nil, "registry")
+	})
+
+	want := []string{"apply vnet", "apply app", "destroy app", "destroy vnet"}
+	if fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+	if reason := modules[2].BlockedReason; !strings.Contains(reason, "excluded is not part of this run") {
+		t.Errorf("orphan BlockedReason = %q", reason)
+	}
+}
+
+// TestRunModuleTests_DependentsWithOneParallelSlot reruns itself with
+// -test.parallel=1 on one CPU, where dependents outnumber the slots and must
+// not hold the only slot while their dependency waits for it. The race
+// detector shuffles the scheduling enough to hit that order reliably.
+func TestRunModuleTests_DependentsWithOneParallelSlot(t *testing.T) {
+	if os.Getenv("VALIDOR_ONE_PARALLEL_SLOT") == "" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestRunModuleTests_DependentsWithOneParallelSlot$", "-test.parallel=1", "-test.cpu=1", "-test.count=20", "-test.timeout=30s")
+		cmd.Env = append(os.Environ(), "VALIDOR_ONE_PARALLEL_SLOT=1")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("run with one parallel slot failed: %v\n%s", err, output)
+		}
+		return
+	}
+
+	var mu sync.Mutex
+	applied := make(map[string]bool)
+	var modules []*Module
+	for _, module := range []*Module{
+		moduleWithDeps("app1", "vnet"),
+		moduleWithDeps("app2", "vnet"),
+		moduleWithDeps("app3", "vnet"),
+		moduleWithDeps("vnet"),
+	} {
+		module.Path = t.TempDir()
+		module.applyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, dependency := range m.dependsOn() {
+				if !applied[dependency] {
+					tb.Errorf("%s applied before its dependency %s", m.Name, dependency)
+				}
+			}
+			applied[m.Name] = true
+			return nil
+		}
+		module.destroyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+			return nil
+		}
+		modules = append(modules, module)
+	}
+
+	runModuleTests(t, modules, true, NewConfig(), nil, "registry")
+}
+
+func TestPrintModuleSummary_Blocked(t *testing.T) {
+	failed := NewModule("vnet", "")
+	failed.Errors = []error{fmt.Errorf("boom")}
+	blocked := NewModule("app", "")
+	blocked.BlockedReason = "dependency vnet failed"
+
+	tb := &mockTB{}
+	PrintModuleSummary(tb, []*Module{failed, blocked})
+
+	output := strings.Join(tb.logs, "\n")
+	for _, want := range []string{"Module app blocked: dependency vnet failed", "1 of 2 modules failed, 1 blocked"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("summary missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestPrintModuleSummary_Interrupted(t *testing.T) {
+	module := NewModule("mod1", "")
+	module.Interrupted = true
+	module.Errors = []error{context.Canceled}
+
+	tb := &mockTB{}
+	PrintModuleSummary(tb, []*Module{module, NewModule("mod2", "")})
+
+	output := strings.Join(tb.logs, "\n")
+	for _, want := range []string{"Module mod1 interrupted", "context canceled", "0 of 2 modules failed, 1 interrupted"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("summary missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestRunModuleTests_ManifestSkip(t *testing.T) {
+	skipped := moduleWithDeps("skipped")
+	skipped.Manifest.Skip = true
+	skipped.Manifest.SkipReason = "quota exhausted"
+	dependent := moduleWithDeps("dependent", "skipped")
+
+	var applied []string
+	for _, module := range []*Module{skipped, dependent} {
+		module.Path = t.TempDir()
+		module.applyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+			applied = append(applied, m.Name)
+			return nil
+		}
+		module.destroyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+			return nil
+		}
+	}
+
+	t.Run("examples", func(t *testing.T) {
+		runModuleTests(t, []*Module{skipped, dependent}, false, &Config{}, nil, "registry")
+	})
+
+	if len(applied) != 0 {
+		t.Errorf("no example should be applied, got %v", applied)
+	}
+	if skipped.SkipReason != "quota exhausted" {
+		t.Errorf("SkipReason = %q", skipped.SkipReason)
+	}
+	if dependent.BlockedReason != "dependency skipped was skipped" {
+		t.Errorf("BlockedReason = %q", dependent.BlockedReason)
+	}
+}
+
+func TestPrintModuleSummary_Skipped(t *testing.T) {
+	skipped := NewModule("mod1", "")
+	skipped.SkipReason = "quota exhausted"
+
+	tb := &mockTB{}
+	PrintModuleSummary(tb, []*Module{skipped, NewModule("mod2", "")})
+
+	output := strings.Join(tb.logs, "\n")
+	for _, want := range []string{"Module mod1 skipped: quota exhausted", "All 1 modules applied and destroyed successfully, 1 skipped"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("summary missing %q:\n%s", want, output)
+		}
+	}
+}
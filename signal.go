@@ -0,0 +1,51 @@
+package validor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+)
+
+var errInterrupted = errors.New("run interrupted")
+
+// interruptSignals is a variable so tests can use a signal that does not
+// terminate the test binary when the handler is not yet installed.
+var interruptSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// interruptContext returns a context that is cancelled on the first SIGINT
+// or SIGTERM. In-flight terraform commands are interrupted through their
+// contexts, while destroys run on detached contexts and still complete.
+// After the first signal the default handling is restored, so a second one
+// terminates the process immediately.
+func interruptContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, interruptSignals...)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+			fmt.Printf("Received %s, not starting new examples and destroying applied ones. Send it again to exit immediately.\n", sig)
+			cancel(fmt.Errorf("%w by %s", errInterrupted, sig))
+		case <-done:
+		}
+	}()
+
+	t.Cleanup(func() {
+		signal.Stop(signals)
+		close(done)
+		cancel(nil)
+	})
+	return ctx
+}
+
+func interrupted(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errInterrupted)
+}
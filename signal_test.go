@@ -0,0 +1,99 @@
+//go:build unix
+
+package validor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestInterruptContext(t *testing.T) {
+	original := interruptSignals
+	interruptSignals = []os.Signal{syscall.SIGUSR1}
+	t.Cleanup(func() { interruptSignals = original })
+
+	var ctx context.Context
+	t.Run("run", func(t *testing.T) {
+		ctx = interruptContext(t)
+		if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("context was not cancelled by the signal")
+		}
+		if !interrupted(ctx) {
+			t.Errorf("expected interrupted cause, got %v", context.Cause(ctx))
+		}
+	})
+
+	if ctx.Err() == nil {
+		t.Error("context should be cancelled once the test finishes")
+	}
+}
+
+func TestInterruptContext_CleanupIsNotAnInterrupt(t *testing.T) {
+	var ctx context.Context
+	t.Run("run", func(t *testing.T) {
+		ctx = interruptContext(t)
+	})
+
+	if ctx.Err() == nil || interrupted(ctx) {
+		t.Errorf("cleanup should cancel without an interrupt cause, got %v", context.Cause(ctx))
+	}
+}
+
+func TestModuleRunner_Interrupted(t *testing.T) {
+	newRunner := func(t *testing.T, modules ...*Module) *moduleRunner {
+		graph, err := newDependencyGraph(modules)
+		if err != nil {
+			t.Fatal(err)
+		}
+		runner := newModuleRunner(&Config{}, "registry")
+		runner.graph = graph
+		return runner
+	}
+
+	t.Run("not started after interrupt", func(t *testing.T) {
+		var applied bool
+		module := NewModule("mod1", t.TempDir())
+		module.applyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+			applied = true
+			return nil
+		}
+
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(fmt.Errorf("%w by test", errInterrupted))
+		newRunner(t, module).run(ctx, t, module)
+
+		if applied || !module.Interrupted {
+			t.Errorf("applied = %v, Interrupted = %v, want false, true", applied, module.Interrupted)
+		}
+	})
+
+	t.Run("in-flight apply is destroyed", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		var destroyed bool
+		module := NewModule("mod1", t.TempDir())
+		module.applyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+			cancel(fmt.Errorf("%w by test", errInterrupted))
+			return nil
+		}
+		module.destroyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+			destroyed = ctx.Err() == nil
+			return nil
+		}
+
+		newRunner(t, module).run(ctx, t, module)
+
+		if !module.Interrupted || !destroyed {
+			t.Errorf("Interrupted = %v, destroyed with live context = %v, want true, true", module.Interrupted, destroyed)
+		}
+	})
+}
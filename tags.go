@@ -0,0 +1,251 @@
+package validor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// tagHeaderPrefix marks a comment in the leading comment block of a .tf file
+// that lists tags, e.g. "# validor:tags smoke, network".
+const tagHeaderPrefix = "validor:tags"
+
+// TagExpr is a parsed tag expression such as "smoke && !slow".
+type TagExpr interface {
+	Match(tags []string) bool
+}
+
+type tagName string
+
+func (n tagName) Match(tags []string) bool { return slices.Contains(tags, string(n)) }
+
+type tagNot struct{ expr TagExpr }
+
+func (n tagNot) Match(tags []string) bool { return !n.expr.Match(tags) }
+
+type tagAnd struct{ left, right TagExpr }
+
+func (a tagAnd) Match(tags []string) bool { return a.left.Match(tags) && a.right.Match(tags) }
+
+type tagOr struct{ left, right TagExpr }
+
+func (o tagOr) Match(tags []string) bool { return o.left.Match(tags) || o.right.Match(tags) }
+
+// ParseTagExpr parses tag names combined with !, &&, || and parentheses.
+// A comma is accepted as a shorthand for ||.
+func ParseTagExpr(expr string) (TagExpr, error) {
+	p := &tagParser{tokens: tokenizeTagExpr(expr)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty tag expression")
+	}
+	parsed, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag expression %q: %w", expr, err)
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("invalid tag expression %q: unexpected %q", expr, p.tokens[p.pos])
+	}
+	return parsed, nil
+}
+
+func tokenizeTagExpr(expr string) []string {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case strings.HasPrefix(expr[i:], "&&"), strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, expr[i:i+2])
+			i += 2
+		case c == '!' || c == '(' || c == ')' || c == ',' || c == '&' || c == '|':
+			tokens = append(tokens, string(c))
+			i++
+		default:
+			start := i
+			for i < len(expr) && isTagChar(rune(expr[i])) {
+				i++
+			}
+			if i == start {
+				i++
+			}
+			tokens = append(tokens, expr[start:i])
+		}
+	}
+	return tokens
+}
+
+func isTagChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_.:/", r)
+}
+
+type tagParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *tagParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *tagParser) parseOr() (TagExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" || p.peek() == "," {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = tagOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *tagParser) parseAnd() (TagExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = tagAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *tagParser) parseUnary() (TagExpr, error) {
+	token := p.peek()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case token == "!":
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return tagNot{expr}, nil
+	case token == "(":
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return expr, nil
+	case isTagChar([]rune(token)[0]):
+		p.pos++
+		return tagName(token), nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", token)
+	}
+}
+
+// Tags returns the tags of the example from its manifest and from
+// "validor:tags" comments at the top of its .tf files.
+func (m *Module) Tags() ([]string, error) {
+	var tags []string
+	if m.Manifest != nil {
+		tags = append(tags, m.Manifest.Tags...)
+	}
+
+	files, err := filepath.Glob(filepath.Join(m.Path, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		headerTags, err := readTagHeader(file)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, headerTags...)
+	}
+
+	slices.Sort(tags)
+	return slices.Compact(tags), nil
+}
+
+func readTagHeader(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var tags []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		comment, ok := strings.CutPrefix(line, "#")
+		if !ok {
+			comment, ok = strings.CutPrefix(line, "//")
+		}
+		if !ok {
+			break
+		}
+		if list, found := strings.CutPrefix(strings.TrimSpace(comment), tagHeaderPrefix); found {
+			tags = append(tags, strings.FieldsFunc(list, func(r rune) bool {
+				return r == ',' || unicode.IsSpace(r)
+			})...)
+		}
+	}
+	return tags, scanner.Err()
+}
+
+// filterModulesByTags keeps the modules matching config.Tags and not
+// matching config.ExcludeTags. Manifests must already be loaded.
+func filterModulesByTags(modules []*Module, config *Config) ([]*Module, error) {
+	if config == nil || (config.Tags == "" && config.ExcludeTags == "") {
+		return modules, nil
+	}
+
+	var include, exclude TagExpr
+	var err error
+	if config.Tags != "" {
+		if include, err = ParseTagExpr(config.Tags); err != nil {
+			return nil, err
+		}
+	}
+	if config.ExcludeTags != "" {
+		if exclude, err = ParseTagExpr(config.ExcludeTags); err != nil {
+			return nil, err
+		}
+	}
+
+	var selected []*Module
+	for _, module := range modules {
+		tags, err := module.Tags()
+		if err != nil {
+			return nil, err
+		}
+		if include != nil && !include.Match(tags) {
+			fmt.Printf("Skipping module %s as its tags %v do not match %q\n", module.Name, tags, config.Tags)
+			continue
+		}
+		if exclude != nil && exclude.Match(tags) {
+			fmt.Printf("Skipping module %s as its tags %v match the excluded %q\n", module.Name, tags, config.ExcludeTags)
+			continue
+		}
+		selected = append(selected, module)
+	}
+	return selected, nil
+}
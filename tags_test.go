@@ -0,0 +1,108 @@
+package validor
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTagExpr(t *testing.T) {
+	tests := []struct {
+		expr    string
+		tags    []string
+		want    bool
+		wantErr bool
+	}{
+		{expr: "smoke", tags: []string{"smoke"}, want: true},
+		{expr: "smoke", tags: []string{"slow"}, want: false},
+		{expr: "smoke && !slow", tags: []string{"smoke"}, want: true},
+		{expr: "smoke && !slow", tags: []string{"smoke", "slow"}, want: false},
+		{expr: "network || storage", tags: []string{"storage"}, want: true},
+		{expr: "network, storage", tags: []string{"network"}, want: true},
+		{expr: "smoke || network && slow", tags: []string{"smoke"}, want: true},
+		{expr: "(smoke || network) && slow", tags: []string{"smoke"}, want: false},
+		{expr: "!(a || b)", tags: []string{"c"}, want: true},
+		{expr: "!!a", tags: []string{"a"}, want: true},
+		{expr: "azure:westeurope", tags: []string{"azure:westeurope"}, want: true},
+		{expr: "", wantErr: true},
+		{expr: "smoke &&", wantErr: true},
+		{expr: "smoke & slow", wantErr: true},
+		{expr: "(smoke", wantErr: true},
+		{expr: "smoke)", wantErr: true},
+		{expr: "smoke slow", wantErr: true},
+		{expr: "@smoke", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := ParseTagExpr(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTagExpr(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if err == nil && expr.Match(tt.tags) != tt.want {
+				t.Errorf("ParseTagExpr(%q).Match(%v) = %v, want %v", tt.expr, tt.tags, !tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestModule_Tags(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "main.tf"), `# Example with a virtual network.
+# validor:tags network, smoke
+
+# validor:tags slow
+module "network" {
+  # validor:tags ignored
+}
+`)
+	writeFile(t, filepath.Join(dir, "variables.tf"), "// validor:tags smoke expensive\n")
+
+	module := NewModule("default", dir)
+	module.Manifest = &Manifest{Tags: []string{"azure"}}
+
+	tags, err := module.Tags()
+	if err != nil {
+		t.Fatalf("Tags() error = %v", err)
+	}
+	if got, want := fmt.Sprint(tags), "[azure expensive network slow smoke]"; got != want {
+		t.Errorf("Tags() = %s, want %s", got, want)
+	}
+}
+
+func TestDiscoverModules_FiltersByTags(t *testing.T) {
+	examplesPath := t.TempDir()
+	writeFile(t, filepath.Join(examplesPath, "default", "main.tf"), "# validor:tags smoke\n")
+	writeFile(t, filepath.Join(examplesPath, "complete", "main.tf"), "# validor:tags smoke\n")
+	writeFile(t, filepath.Join(examplesPath, "complete", ManifestFileName), "tags: [slow]\n")
+	writeFile(t, filepath.Join(examplesPath, "private", "main.tf"), "")
+
+	tests := []struct {
+		name    string
+		config  *Config
+		want    string
+		wantErr bool
+	}{
+		{name: "no filter", config: &Config{}, want: "complete,default,private"},
+		{name: "include", config: &Config{Tags: "smoke"}, want: "complete,default"},
+		{name: "include and exclude", config: &Config{Tags: "smoke", ExcludeTags: "slow"}, want: "default"},
+		{name: "expression", config: &Config{Tags: "smoke && !slow"}, want: "default"},
+		{name: "exclude only", config: &Config{ExcludeTags: "smoke"}, want: "private"},
+		{name: "invalid", config: &Config{Tags: "smoke &&"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewModuleManager(examplesPath)
+			manager.SetConfig(tt.config)
+
+			modules, err := manager.DiscoverModules()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DiscoverModules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := moduleNames(modules); err == nil && got != tt.want {
+				t.Errorf("DiscoverModules() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package validor
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// deadlineMargin is kept free before the go test deadline so that the
+	// summary and t.Cleanup functions still get to run.
+	deadlineMargin = 30 * time.Second
+
+	// defaultDestroyReserve is held back from the apply budget when no
+	// destroy timeout is configured.
+	defaultDestroyReserve = 5 * time.Minute
+)
+
+type deadlineFunc func() (time.Time, bool)
+
+func exampleContext(ctx context.Context, config *Config, testDeadline deadlineFunc) (context.Context, context.CancelFunc) {
+	limit, ok := testDeadline()
+	return withBudget(ctx, config.ExampleTimeout, limit.Add(-deadlineMargin), ok)
+}
+
+func applyContext(ctx context.Context, config *Config, testDeadline deadlineFunc) (context.Context, context.CancelFunc) {
+	limit, ok := testDeadline()
+	if ok {
+		limit = limit.Add(-deadlineMargin)
+		limit = limit.Add(-destroyReserve(config, time.Until(limit)))
+	}
+	return withBudget(ctx, config.ApplyTimeout, limit, ok)
+}
+
+// destroyContext is detached from ctx on purpose: an expired example or apply
+// budget must not prevent the resources it created from being destroyed.
+func destroyContext(ctx context.Context, config *Config, testDeadline deadlineFunc) (context.Context, context.CancelFunc) {
+	limit, ok := testDeadline()
+	return withBudget(context.WithoutCancel(ctx), config.DestroyTimeout, limit.Add(-deadlineMargin), ok)
+}
+
+func destroyReserve(config *Config, remaining time.Duration) time.Duration {
+	reserve := config.DestroyTimeout
+	if reserve == 0 {
+		reserve = defaultDestroyReserve
+	}
+	return min(reserve, max(remaining/2, 0))
+}
+
+func withBudget(ctx context.Context, timeout time.Duration, limit time.Time, hasLimit bool) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		deadline := time.Now().Add(timeout)
+		if !hasLimit || deadline.Before(limit) {
+			limit, hasLimit = deadline, true
+		}
+	}
+	if !hasLimit {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, limit)
+}
+
+func timeoutOperation(operation string, err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return operation + " timeout"
+	}
+	return operation
+}
@@ -0,0 +1,104 @@
+package validor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func noDeadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func deadlineIn(d time.Duration) deadlineFunc {
+	deadline := time.Now().Add(d)
+	return func() (time.Time, bool) { return deadline, true }
+}
+
+func TestExampleContext(t *testing.T) {
+	t.Run("no timeout and no test deadline", func(t *testing.T) {
+		ctx, cancel := exampleContext(context.Background(), &Config{}, noDeadline)
+		defer cancel()
+		if _, ok := ctx.Deadline(); ok {
+			t.Error("expected no deadline")
+		}
+	})
+
+	t.Run("example timeout", func(t *testing.T) {
+		ctx, cancel := exampleContext(context.Background(), &Config{ExampleTimeout: time.Minute}, noDeadline)
+		defer cancel()
+		deadline, ok := ctx.Deadline()
+		if !ok || time.Until(deadline) > time.Minute {
+			t.Errorf("expected deadline within a minute, got %v", deadline)
+		}
+	})
+
+	t.Run("test deadline wins when earlier", func(t *testing.T) {
+		ctx, cancel := exampleContext(context.Background(), &Config{ExampleTimeout: time.Hour}, deadlineIn(10*time.Minute))
+		defer cancel()
+		deadline, ok := ctx.Deadline()
+		if !ok || time.Until(deadline) > 10*time.Minute-deadlineMargin {
+			t.Errorf("expected deadline before the test deadline minus margin, got %v", time.Until(deadline))
+		}
+	})
+}
+
+func TestApplyContext(t *testing.T) {
+	t.Run("reserves destroy timeout before test deadline", func(t *testing.T) {
+		config := &Config{DestroyTimeout: 10 * time.Minute}
+		ctx, cancel := applyContext(context.Background(), config, deadlineIn(time.Hour))
+		defer cancel()
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			t.Fatal("expected apply deadline")
+		}
+		remaining := time.Until(deadline)
+		if remaining > time.Hour-10*time.Minute-deadlineMargin || remaining < 45*time.Minute {
+			t.Errorf("expected apply budget of about 49 minutes, got %v", remaining)
+		}
+	})
+
+	t.Run("reserve never takes more than half of the remaining time", func(t *testing.T) {
+		ctx, cancel := applyContext(context.Background(), &Config{}, deadlineIn(4*time.Minute))
+		defer cancel()
+		deadline, _ := ctx.Deadline()
+		if remaining := time.Until(deadline); remaining < time.Minute {
+			t.Errorf("expected apply to keep about half of the remaining time, got %v", remaining)
+		}
+	})
+
+	t.Run("apply timeout", func(t *testing.T) {
+		ctx, cancel := applyContext(context.Background(), &Config{ApplyTimeout: time.Minute}, deadlineIn(time.Hour))
+		defer cancel()
+		deadline, _ := ctx.Deadline()
+		if time.Until(deadline) > time.Minute {
+			t.Errorf("expected apply timeout to win, got %v", time.Until(deadline))
+		}
+	})
+}
+
+func TestDestroyContext(t *testing.T) {
+	parent, cancelParent := context.WithCancel(context.Background())
+	cancelParent()
+
+	ctx, cancel := destroyContext(parent, &Config{DestroyTimeout: time.Minute}, noDeadline)
+	defer cancel()
+
+	if ctx.Err() != nil {
+		t.Fatal("destroy context should not inherit cancellation from the example")
+	}
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > time.Minute {
+		t.Errorf("expected destroy timeout to apply, got %v", deadline)
+	}
+}
+
+func TestTimeoutOperation(t *testing.T) {
+	if got := timeoutOperation("terraform apply", fmt.Errorf("wrapped: %w", context.DeadlineExceeded)); got != "terraform apply timeout" {
+		t.Errorf("timeoutOperation() = %q, want terraform apply timeout", got)
+	}
+	if got := timeoutOperation("terraform apply", errors.New("boom")); got != "terraform apply" {
+		t.Errorf("timeoutOperation() = %q, want terraform apply", got)
+	}
+}
@@ -0,0 +1,91 @@
+package validor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+const (
+	defaultTerraformBinary = "terraform"
+	openTofuBinary         = "tofu"
+)
+
+// Toolchain is the terraform compatible binary a run uses.
+type Toolchain struct {
+	Binary  string
+	Version string
+}
+
+func (tc Toolchain) String() string {
+	name := filepath.Base(tc.Binary)
+	if tc.Version == "" {
+		return name + " (unknown version)"
+	}
+	return name + " " + tc.Version
+}
+
+// resolveTerraformBinary returns the configured binary, or detects one on
+// PATH. terraform is preferred over tofu when both are installed.
+func resolveTerraformBinary(config *Config) (string, error) {
+	if config.TerraformBinary != "" {
+		path, err := exec.LookPath(config.TerraformBinary)
+		if err != nil {
+			return "", fmt.Errorf("terraform binary %q not found: %w", config.TerraformBinary, err)
+		}
+		return path, nil
+	}
+	for _, candidate := range []string{defaultTerraformBinary, openTofuBinary} {
+		if path, err := exec.LookPath(candidate); err == nil {
+			return path, nil
+		}
+	}
+	return defaultTerraformBinary, nil
+}
+
+func detectToolchain(ctx context.Context, t testLogger, binary string) Toolchain {
+	toolchain := Toolchain{Binary: binary}
+	stdout, err := runTerraform(ctx, t, &terraform.Options{TerraformBinary: binary}, "version", "-json")
+	if err != nil {
+		return toolchain
+	}
+	toolchain.Version = parseVersionOutput(stdout)
+	return toolchain
+}
+
+// parseVersionOutput reads the version from `version -json`. OpenTofu uses
+// the same terraform_version key as terraform.
+func parseVersionOutput(output string) string {
+	var version struct {
+		TerraformVersion string `json:"terraform_version"`
+	}
+	if err := json.Unmarshal([]byte(output), &version); err == nil && version.TerraformVersion != "" {
+		return version.TerraformVersion
+	}
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+	if _, v, ok := strings.Cut(firstLine, " v"); ok {
+		return v
+	}
+	return ""
+}
+
+// setupToolchain points every module still using the default binary at the
+// resolved one and returns the toolchain for the summary.
+func setupToolchain(ctx context.Context, t *testing.T, config *Config, modules []*Module) Toolchain {
+	binary, err := resolveTerraformBinary(config)
+	if err != nil {
+		t.Fatal(redError(err.Error()))
+	}
+	for _, module := range modules {
+		if module.Options.TerraformBinary == defaultTerraformBinary {
+			module.Options.TerraformBinary = binary
+		}
+	}
+	return detectToolchain(ctx, t, binary)
+}
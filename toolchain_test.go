@@ -0,0 +1,107 @@
+package validor
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseVersionOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{name: "terraform json", output: `{"terraform_version":"1.9.8","platform":"linux_amd64"}`, want: "1.9.8"},
+		{name: "tofu json", output: `{"terraform_version":"1.8.5","platform":"linux_amd64","provider_selections":{}}`, want: "1.8.5"},
+		{name: "plain text", output: "OpenTofu v1.6.0\non linux_amd64\n", want: "1.6.0"},
+		{name: "unknown", output: "something else", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseVersionOutput(tt.output); got != tt.want {
+				t.Errorf("parseVersionOutput() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveTerraformBinary(t *testing.T) {
+	t.Run("detects tofu when terraform is missing", func(t *testing.T) {
+		dir := t.TempDir()
+		tofu := writeScriptAt(t, filepath.Join(dir, "tofu"), "exit 0\n")
+		t.Setenv("PATH", dir)
+
+		got, err := resolveTerraformBinary(&Config{})
+		if err != nil || got != tofu {
+			t.Errorf("resolveTerraformBinary() = %q, %v, want %q", got, err, tofu)
+		}
+	})
+
+	t.Run("prefers terraform over tofu", func(t *testing.T) {
+		dir := t.TempDir()
+		writeScriptAt(t, filepath.Join(dir, "tofu"), "exit 0\n")
+		terraform := writeScriptAt(t, filepath.Join(dir, "terraform"), "exit 0\n")
+		t.Setenv("PATH", dir)
+
+		got, err := resolveTerraformBinary(&Config{})
+		if err != nil || got != terraform {
+			t.Errorf("resolveTerraformBinary() = %q, %v, want %q", got, err, terraform)
+		}
+	})
+
+	t.Run("falls back to terraform", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+
+		got, err := resolveTerraformBinary(&Config{})
+		if err != nil || got != "terraform" {
+			t.Errorf("resolveTerraformBinary() = %q, %v, want terraform", got, err)
+		}
+	})
+
+	t.Run("configured binary", func(t *testing.T) {
+		binary := writeScriptAt(t, filepath.Join(t.TempDir(), "tofu-1.8"), "exit 0\n")
+
+		got, err := resolveTerraformBinary(&Config{TerraformBinary: binary})
+		if err != nil || got != binary {
+			t.Errorf("resolveTerraformBinary() = %q, %v, want %q", got, err, binary)
+		}
+	})
+
+	t.Run("configured binary missing", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+
+		if _, err := resolveTerraformBinary(&Config{TerraformBinary: "tofu"}); err == nil {
+			t.Error("expected an error for a missing binary")
+		}
+	})
+}
+
+func TestSetupToolchain(t *testing.T) {
+	dir := t.TempDir()
+	tofu := writeScriptAt(t, filepath.Join(dir, "tofu"), `echo '{"terraform_version":"1.8.5"}'`+"\n")
+	t.Setenv("PATH", dir)
+
+	defaultModule := NewModule("default", t.TempDir())
+	customModule := NewModule("custom", t.TempDir())
+	customModule.Options.TerraformBinary = "/opt/terraform"
+
+	toolchain := setupToolchain(testContext(t), t, &Config{}, []*Module{defaultModule, customModule})
+
+	if toolchain.Binary != tofu || toolchain.Version != "1.8.5" {
+		t.Errorf("toolchain = %+v, want %s 1.8.5", toolchain, tofu)
+	}
+	if got := toolchain.String(); got != "tofu 1.8.5" {
+		t.Errorf("String() = %q, want tofu 1.8.5", got)
+	}
+	if defaultModule.Options.TerraformBinary != tofu {
+		t.Errorf("default module binary = %q, want %q", defaultModule.Options.TerraformBinary, tofu)
+	}
+	if customModule.Options.TerraformBinary != "/opt/terraform" {
+		t.Errorf("custom module binary should be kept, got %q", customModule.Options.TerraformBinary)
+	}
+	if got := (Toolchain{Binary: "terraform"}).String(); !strings.Contains(got, "unknown version") {
+		t.Errorf("String() without version = %q", got)
+	}
+}
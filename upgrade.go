@@ -0,0 +1,56 @@
+package validor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func (m *Module) Upgrade(ctx context.Context, t *testing.T, converter SourceConverter, moduleInfo ModuleInfo, apply bool) ([]FileRestore, error) {
+	t.Helper()
+
+	t.Logf("Upgrading Terraform module %s from registry to local source", m.Name)
+
+	filesToRestore, err := converter.ConvertToLocal(ctx, m.Path, moduleInfo)
+	if err != nil {
+		return filesToRestore, m.recordError(t, "upgrade", fmt.Errorf("failed to convert to local source: %w", err))
+	}
+
+	plan, err := m.runPlan(ctx, t, true)
+	if err != nil {
+		return filesToRestore, m.recordError(t, "upgrade plan", err)
+	}
+
+	if destructive := destructiveChanges(plan); len(destructive) > 0 {
+		return filesToRestore, m.recordError(t, "upgrade", fmt.Errorf(
+			"upgrading to the local source destroys or replaces %d resource(s): %s",
+			len(destructive), strings.Join(destructive, ", ")))
+	}
+
+	if !apply {
+		return filesToRestore, nil
+	}
+
+	if m.applyHook != nil {
+		err = m.applyHook(ctx, t, m)
+	} else {
+		_, err = m.retryPolicy().do(ctx, t, "terraform apply for module "+m.Name, func() (string, error) {
+			return runTerraform(ctx, t, m.Options, applyArgs(m.Options)...)
+		})
+	}
+	if err != nil {
+		return filesToRestore, m.recordError(t, "upgrade apply", err)
+	}
+	return filesToRestore, nil
+}
+
+func restoreFiles(filesToRestore []FileRestore) error {
+	for _, restore := range filesToRestore {
+		if err := os.WriteFile(restore.Path, []byte(restore.OriginalContent), 0o644); err != nil {
+			return fmt.Errorf("failed to restore file %s: %w", restore.Path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package validor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func setupUpgradeExample(t *testing.T) (string, string) {
+	t.Helper()
+	modDir := t.TempDir()
+	original := `
+module "test" {
+  source  = "cloudnationhq/mymodule/azure"
+  version = "~> 1.0"
+}
+`
+	tfFile := filepath.Join(modDir, "main.tf")
+	if err := os.WriteFile(tfFile, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to create terraform file: %v", err)
+	}
+	return modDir, original
+}
+
+func TestModule_Upgrade(t *testing.T) {
+	moduleInfo := ModuleInfo{Name: "mymodule", Provider: "azure", Namespace: "cloudnationhq"}
+
+	t.Run("non-destructive plan passes and converts source", func(t *testing.T) {
+		modDir, original := setupUpgradeExample(t)
+		module := NewModule("example1", modDir)
+		module.planHook = func(ctx context.Context, tb *testing.T, m *Module) (*terraform.PlanStruct, error) {
+			content, _ := os.ReadFile(filepath.Join(m.Path, "main.tf"))
+			if !strings.Contains(string(content), `"../../"`) {
+				t.Errorf("expected plan to run against local source, got %s", content)
+			}
+			return planWithChanges(map[string]tfjson.Actions{
+				"module.test.azurerm_virtual_network.this": {tfjson.ActionUpdate},
+			}), nil
+		}
+
+		converter := NewSourceConverter(&mockRegistryClient{latestVersion: "1.0.0"})
+		filesToRestore, err := module.Upgrade(testContext(t), t, converter, moduleInfo, false)
+		if err != nil {
+			t.Fatalf("Upgrade() error = %v", err)
+		}
+		if len(filesToRestore) != 1 {
+			t.Fatalf("expected 1 file to restore, got %d", len(filesToRestore))
+		}
+
+		if err := restoreFiles(filesToRestore); err != nil {
+			t.Fatalf("restoreFiles() error = %v", err)
+		}
+		content, _ := os.ReadFile(filepath.Join(modDir, "main.tf"))
+		if string(content) != original {
+			t.Fatalf("expected original content to be restored, got %s", content)
+		}
+	})
+
+	t.Run("destroy and replace actions fail with addresses", func(t *testing.T) {
+		modDir, _ := setupUpgradeExample(t)
+		module := NewModule("example1", modDir)
+		module.planHook = func(ctx context.Context, tb *testing.T, m *Module) (*terraform.PlanStruct, error) {
+			return planWithChanges(map[string]tfjson.Actions{
+				"module.test.azurerm_subnet.this":          {tfjson.ActionDelete, tfjson.ActionCreate},
+				"module.test.azurerm_network_security.nsg": {tfjson.ActionDelete},
+				"module.test.azurerm_route_table.rt":       {tfjson.ActionUpdate},
+			}), nil
+		}
+
+		converter := NewSourceConverter(&mockRegistryClient{latestVersion: "1.0.0"})
+		_, err := module.Upgrade(testContext(t), &testing.T{}, converter, moduleInfo, true)
+		if err == nil {
+			t.Fatalf("expected upgrade to fail on destructive changes")
+		}
+
+		var moduleErr *ModuleError
+		if !errors.As(err, &moduleErr) || moduleErr.Operation != "upgrade" {
+			t.Fatalf("expected ModuleError with upgrade operation, got %v", err)
+		}
+		for _, address := range []string{"module.test.azurerm_subnet.this", "module.test.azurerm_network_security.nsg"} {
+			if !strings.Contains(err.Error(), address) {
+				t.Errorf("expected error to mention %s, got %v", address, err)
+			}
+		}
+		if strings.Contains(err.Error(), "azurerm_route_table") {
+			t.Errorf("update actions should not be reported as destructive: %v", err)
+		}
+	})
+
+	t.Run("applies local source when requested", func(t *testing.T) {
+		modDir, _ := setupUpgradeExample(t)
+		module := NewModule("example1", modDir)
+		module.planHook = func(ctx context.Context, tb *testing.T, m *Module) (*terraform.PlanStruct, error) {
+			return &terraform.PlanStruct{}, nil
+		}
+		var applied bool
+		module.applyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+			applied = true
+			return nil
+		}
+
+		converter := NewSourceConverter(&mockRegistryClient{latestVersion: "1.0.0"})
+		if _, err := module.Upgrade(testContext(t), t, converter, moduleInfo, true); err != nil {
+			t.Fatalf("Upgrade() error = %v", err)
+		}
+		if !applied {
+			t.Fatalf("expected local source to be applied")
+		}
+	})
+}
+
+func TestRunModuleTests_UpgradeRejectsLocalSource(t *testing.T) {
+	module := NewModule("default", t.TempDir())
+	module.applyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+		t.Error("apply should not run when upgrade is combined with a local source")
+		return nil
+	}
+
+	mockT := &testing.T{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runModuleTests(mockT, []*Module{module}, false, NewConfig(WithUpgrade(true)), nil, "local")
+	}()
+	<-done
+
+	if !mockT.Failed() {
+		t.Error("expected upgrade with a local source to fail the run")
+	}
+}
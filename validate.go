@@ -0,0 +1,118 @@
+package validor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+type DiagnosticError struct {
+	Severity string
+	Summary  string
+	Detail   string
+	File     string
+	Line     int
+	Column   int
+}
+
+func (e *DiagnosticError) Error() string {
+	message := e.Summary
+	if e.Detail != "" {
+		message = fmt.Sprintf("%s: %s", e.Summary, e.Detail)
+	}
+	if e.File == "" {
+		return message
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, message)
+}
+
+func (m *Module) Validate(ctx context.Context, t *testing.T) error {
+	t.Helper()
+
+	var err error
+	if m.validateHook != nil {
+		err = m.validateHook(ctx, t, m)
+	} else {
+		err = m.validate(ctx, t)
+	}
+	m.Validated = err == nil
+	return err
+}
+
+func (m *Module) validate(ctx context.Context, t *testing.T) error {
+	t.Helper()
+
+	t.Logf("Validating Terraform module: %s", m.Name)
+
+	options, err := m.Options.Clone()
+	if err != nil {
+		return m.recordError(t, "terraform validate", fmt.Errorf("failed to clone terraform options: %w", err))
+	}
+
+	var validateErr error
+	stdout, err := runTerraform(ctx, t, options, "fmt", "-check", "-diff", "-no-color")
+	if err != nil {
+		validateErr = m.recordError(t, "terraform fmt", fmt.Errorf("files are not formatted:\n%s", strings.TrimSpace(stdout)))
+	}
+
+	select {
+	case <-ctx.Done():
+		return m.recordError(t, "terraform validate", ctx.Err())
+	default:
+	}
+
+	options.ExtraArgs.Init = append(options.ExtraArgs.Init, "-backend=false")
+	if _, err := runTerraform(ctx, t, options, initArgs(options)...); err != nil {
+		return m.recordError(t, "terraform init", err)
+	}
+
+	stdout, err = runTerraform(ctx, t, options, "validate", "-json", "-no-color")
+	diagnostics, parseErr := parseValidateDiagnostics(stdout, m.Path)
+	if parseErr != nil {
+		if err != nil {
+			return m.recordError(t, "terraform validate", err)
+		}
+		return m.recordError(t, "terraform validate", parseErr)
+	}
+
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity != string(tfjson.DiagnosticSeverityError) {
+			t.Logf("Warning in module %s: %v", m.Name, diagnostic)
+			continue
+		}
+		validateErr = m.recordError(t, "terraform validate", diagnostic)
+	}
+
+	if validateErr == nil && err != nil {
+		validateErr = m.recordError(t, "terraform validate", err)
+	}
+	return validateErr
+}
+
+func parseValidateDiagnostics(output, modulePath string) ([]*DiagnosticError, error) {
+	var validateOutput tfjson.ValidateOutput
+	if err := json.Unmarshal([]byte(output), &validateOutput); err != nil {
+		return nil, fmt.Errorf("failed to parse validate output: %w", err)
+	}
+
+	diagnostics := make([]*DiagnosticError, 0, len(validateOutput.Diagnostics))
+	for _, d := range validateOutput.Diagnostics {
+		diagnostic := &DiagnosticError{
+			Severity: string(d.Severity),
+			Summary:  d.Summary,
+			Detail:   d.Detail,
+		}
+		if d.Range != nil {
+			diagnostic.File = filepath.Join(modulePath, d.Range.Filename)
+			diagnostic.Line = d.Range.Start.Line
+			diagnostic.Column = d.Range.Start.Column
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+	return diagnostics, nil
+}
@@ -0,0 +1,107 @@
+package validor
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseValidateDiagnostics(t *testing.T) {
+	output := `{
+  "format_version": "1.0",
+  "valid": false,
+  "error_count": 1,
+  "warning_count": 1,
+  "diagnostics": [
+    {
+      "severity": "error",
+      "summary": "Unsupported argument",
+      "detail": "An argument named \"locaton\" is not expected here.",
+      "range": {
+        "filename": "main.tf",
+        "start": {"line": 12, "column": 3, "byte": 210},
+        "end": {"line": 12, "column": 10, "byte": 217}
+      }
+    },
+    {
+      "severity": "warning",
+      "summary": "Deprecated attribute"
+    }
+  ]
+}`
+
+	diagnostics, err := parseValidateDiagnostics(output, "examples/default")
+	if err != nil {
+		t.Fatalf("parseValidateDiagnostics() error = %v", err)
+	}
+	if len(diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d", len(diagnostics))
+	}
+
+	first := diagnostics[0]
+	if first.Severity != "error" || first.File != filepath.Join("examples/default", "main.tf") || first.Line != 12 || first.Column != 3 {
+		t.Errorf("unexpected first diagnostic: %+v", first)
+	}
+	if got := first.Error(); !strings.HasPrefix(got, filepath.Join("examples/default", "main.tf")+":12:3: Unsupported argument") {
+		t.Errorf("DiagnosticError.Error() = %q", got)
+	}
+
+	second := diagnostics[1]
+	if second.File != "" || second.Error() != "Deprecated attribute" {
+		t.Errorf("unexpected second diagnostic: %+v", second)
+	}
+
+	if _, err := parseValidateDiagnostics("not json", "examples/default"); err == nil {
+		t.Error("expected error for invalid validate output")
+	}
+}
+
+func TestRunModuleTests_Validate(t *testing.T) {
+	module := NewModule("valid", t.TempDir())
+	var validated bool
+	module.validateHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+		validated = true
+		return nil
+	}
+	module.applyHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+		t.Errorf("apply should not run in validate mode")
+		return nil
+	}
+
+	runModuleTests(t, []*Module{module}, false, &Config{Validate: true}, nil, "registry")
+
+	if !validated || !module.Validated {
+		t.Fatalf("expected module %s to be validated", module.Name)
+	}
+}
+
+func TestModule_ValidateRecordsDiagnostics(t *testing.T) {
+	module := NewModule("invalid", t.TempDir())
+	module.validateHook = func(ctx context.Context, tb *testing.T, m *Module) error {
+		return m.recordError(tb, "terraform validate", &DiagnosticError{Severity: "error", Summary: "Missing required argument", File: "main.tf", Line: 4, Column: 1})
+	}
+
+	if err := module.Validate(testContext(t), t); err == nil {
+		t.Fatalf("expected validation error")
+	}
+	if module.Validated || len(module.Errors) != 1 {
+		t.Fatalf("expected one validation error, got %v", module.Errors)
+	}
+	if !strings.Contains(module.Errors[0].Error(), "main.tf:4:1") {
+		t.Errorf("expected error to carry file and line, got %v", module.Errors[0])
+	}
+}
+
+func TestPrintModuleSummary_Validated(t *testing.T) {
+	mock := &mockTB{}
+	module := NewModule("checked", t.TempDir())
+	module.Validated = true
+
+	PrintModuleSummary(mock, []*Module{module})
+
+	joined := strings.Join(mock.logs, "\n")
+	if !strings.Contains(joined, "All 1 modules validated successfully") {
+		t.Fatalf("expected validate success message, got %q", joined)
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -20,11 +19,39 @@ var flagConfig = &Config{
 
 func init() {
 	flag.BoolVar(&flagConfig.SkipDestroy, "skip-destroy", false, "Skip running terraform destroy after apply")
-	flag.StringVar(&flagConfig.Exception, "exception", "", "Comma-separated list of examples to exclude")
-	flag.StringVar(&flagConfig.Example, "example", "", "Specific example(s) to test (comma-separated)")
+	flag.StringVar(&flagConfig.Exception, "exception", "", "Comma-separated list of examples to exclude (globs and re: patterns allowed)")
+	flag.StringVar(&flagConfig.Example, "example", "", "Specific example(s) to test (comma-separated, globs and re: patterns allowed)")
 	flag.BoolVar(&flagConfig.Local, "local", false, "Use local source for testing")
 	flag.StringVar(&flagConfig.Namespace, "namespace", flagConfig.Namespace, "Terraform registry namespace")
+	flag.StringVar(&flagConfig.Tags, "tags", "", "Run only examples whose tags match this expression, e.g. 'smoke && !slow'")
+	flag.StringVar(&flagConfig.ExcludeTags, "exclude-tags", "", "Skip examples whose tags match this expression")
+	flag.StringVar(&flagConfig.ChangedSince, "changed-since", "", "Run only examples changed since this git ref, or all when the module root changed")
 	flag.StringVar(&flagConfig.ExamplesPath, "examples-path", "", "Path to examples directory (defaults to '../examples')")
+	flag.BoolVar(&flagConfig.Idempotency, "idempotency", false, "Fail an example when a second plan after apply is not empty")
+	flag.BoolVar(&flagConfig.Upgrade, "upgrade", false, "Apply the registry source first, then plan the upgrade to the local source")
+	flag.BoolVar(&flagConfig.UpgradeApply, "upgrade-apply", false, "Also apply the local source after a successful upgrade plan")
+	flag.BoolVar(&flagConfig.PlanOnly, "plan-only", false, "Run terraform init and plan only, without apply or destroy")
+	flag.BoolVar(&flagConfig.Validate, "validate", false, "Run terraform fmt, init -backend=false and validate only, without credentials")
+	flag.StringVar(&flagConfig.TerraformBinary, "terraform-binary", "", "Terraform compatible binary to run, such as tofu (defaults to terraform, then tofu, on PATH)")
+	flag.Func("terraform-versions", "Comma-separated terraform versions or binary paths to run every example with", func(value string) error {
+		flagConfig.TerraformVersions = parseExampleList(value)
+		return nil
+	})
+	flag.StringVar(&flagConfig.TerraformBinariesDir, "terraform-binaries-dir", "", "Directory with terraform or tofu binaries per version, in tfenv or tfswitch layout")
+	flag.BoolVar(&flagConfig.Isolate, "isolate", false, "Run every example in a temporary copy of the module root instead of the repository")
+	flag.StringVar(&flagConfig.JournalPath, "journal", "", "Path to a journal file recording which examples were applied and destroyed")
+	flag.BoolVar(&flagConfig.Policy, "policy", false, "Check the plan of every example against the built-in policy rules before apply")
+	flag.Func("allowed-locations", "Comma-separated locations resources may be deployed to, checked before apply", func(value string) error {
+		flagConfig.AllowedLocations = parseExampleList(value)
+		return nil
+	})
+	flag.StringVar(&flagConfig.RetryPolicyFile, "retry-policy", "", "Path to a YAML file with retryable errors, max retries and time between retries")
+	flag.DurationVar(&flagConfig.ExampleTimeout, "example-timeout", 0, "Maximum duration of a single example, excluding destroy")
+	flag.DurationVar(&flagConfig.ApplyTimeout, "apply-timeout", 0, "Maximum duration of terraform apply for a single example")
+	flag.DurationVar(&flagConfig.DestroyTimeout, "destroy-timeout", 0, "Maximum duration of terraform destroy, reserved out of the go test deadline")
+	flag.IntVar(&flagConfig.MaxParallel, "max-parallel", 0, "Maximum number of examples applying at once (0 means no limit)")
+	flag.StringVar(&flagConfig.FixturePath, "fixture-path", "", "Path to the shared fixture applied before all examples (defaults to '<examples-path>/_fixture')")
+	flag.IntVar(&flagConfig.MaxParallelDestroy, "max-parallel-destroy", 0, "Maximum number of examples destroying at once (0 means no limit)")
 }
 
 type Config struct {
@@ -35,6 +62,39 @@ type Config struct {
 	ExceptionList []string
 	Namespace     string
 	ExamplesPath  string
+	Tags          string
+	ExcludeTags   string
+	ChangedSince  string
+	Idempotency   bool
+	Upgrade       bool
+	UpgradeApply  bool
+	PlanOnly      bool
+	Validate      bool
+
+	RetryPolicy     *RetryPolicy
+	RetryPolicyFile string
+
+	ExampleTimeout time.Duration
+	ApplyTimeout   time.Duration
+	DestroyTimeout time.Duration
+
+	MaxParallel        int
+	MaxParallelDestroy int
+
+	FixturePath string
+	JournalPath string
+	Isolate     bool
+
+	TerraformBinary      string
+	TerraformVersions    []string
+	TerraformBinariesDir string
+
+	Policy           bool
+	AllowedLocations []string
+	PolicyRules      []PolicyRule
+
+	hooks     []lifecycleHook
+	workspace *workspace
 }
 
 type Option func(*Config)
@@ -50,6 +110,18 @@ func WithException(exception string) Option {
 	}
 }
 
+func WithTags(expr string) Option {
+	return func(c *Config) { c.Tags = expr }
+}
+
+func WithExcludeTags(expr string) Option {
+	return func(c *Config) { c.ExcludeTags = expr }
+}
+
+func WithChangedSince(ref string) Option {
+	return func(c *Config) { c.ChangedSince = ref }
+}
+
 func WithExample(example string) Option {
 	return func(c *Config) { c.Example = example }
 }
@@ -66,6 +138,92 @@ func WithNamespace(namespace string) Option {
 	return func(c *Config) { c.Namespace = namespace }
 }
 
+func WithIdempotency(check bool) Option {
+	return func(c *Config) { c.Idempotency = check }
+}
+
+func WithUpgrade(upgrade bool) Option {
+	return func(c *Config) { c.Upgrade = upgrade }
+}
+
+func WithUpgradeApply(apply bool) Option {
+	return func(c *Config) { c.UpgradeApply = apply }
+}
+
+func WithPlanOnly(planOnly bool) Option {
+	return func(c *Config) { c.PlanOnly = planOnly }
+}
+
+func WithValidate(validate bool) Option {
+	return func(c *Config) { c.Validate = validate }
+}
+
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(c *Config) { c.RetryPolicy = policy }
+}
+
+func WithRetryPolicyFile(path string) Option {
+	return func(c *Config) { c.RetryPolicyFile = path }
+}
+
+func WithExampleTimeout(timeout time.Duration) Option {
+	return func(c *Config) { c.ExampleTimeout = timeout }
+}
+
+func WithApplyTimeout(timeout time.Duration) Option {
+	return func(c *Config) { c.ApplyTimeout = timeout }
+}
+
+func WithDestroyTimeout(timeout time.Duration) Option {
+	return func(c *Config) { c.DestroyTimeout = timeout }
+}
+
+func WithMaxParallel(limit int) Option {
+	return func(c *Config) { c.MaxParallel = limit }
+}
+
+func WithMaxParallelDestroy(limit int) Option {
+	return func(c *Config) { c.MaxParallelDestroy = limit }
+}
+
+func WithFixturePath(path string) Option {
+	return func(c *Config) { c.FixturePath = path }
+}
+
+func WithJournalPath(path string) Option {
+	return func(c *Config) { c.JournalPath = path }
+}
+
+func WithIsolate(isolate bool) Option {
+	return func(c *Config) { c.Isolate = isolate }
+}
+
+func WithTerraformBinary(binary string) Option {
+	return func(c *Config) { c.TerraformBinary = binary }
+}
+
+func WithTerraformVersions(versions ...string) Option {
+	return func(c *Config) { c.TerraformVersions = versions }
+}
+
+func WithTerraformBinariesDir(dir string) Option {
+	return func(c *Config) { c.TerraformBinariesDir = dir }
+}
+
+func WithPolicy(enabled bool) Option {
+	return func(c *Config) { c.Policy = enabled }
+}
+
+func WithAllowedLocations(locations ...string) Option {
+	return func(c *Config) { c.AllowedLocations = locations }
+}
+
+// WithPolicyRules adds rules that are checked against the plan of every
+// example before apply.
+func WithPolicyRules(rules ...PolicyRule) Option {
+	return func(c *Config) { c.PolicyRules = append(c.PolicyRules, rules...) }
+}
+
 func NewConfig(opts ...Option) *Config {
 	config := &Config{
 		Namespace: "cloudnationhq", // default
@@ -99,7 +257,11 @@ func TestApplyNoError(t *testing.T, opts ...Option) {
 	if config.Example == "" {
 		t.Fatal(redError("-example flag is not set"))
 	}
-	modules := createModulesFromNames(parseExampleList(config.Example), getExamplesPath(config))
+	names, err := resolveExamples(config, parseExampleList(config.Example))
+	if err != nil {
+		t.Fatal(redError(err.Error()))
+	}
+	modules := selectModules(t, createModulesFromNames(names, getExamplesPath(config)), config)
 	sourceType := BoolToStr(config.Local, "local", "registry")
 	var setup TestSetupFunc
 	if config.Local {
@@ -136,6 +298,7 @@ type TestConfig struct {
 	UseLocal     bool
 	Parallel     bool
 	ExamplesPath string
+	Options      []Option
 }
 
 func WithConfig(config *Config) TestOption {
@@ -158,6 +321,12 @@ func WithTestExamplesPath(path string) TestOption {
 	return func(tc *TestConfig) { tc.ExamplesPath = path }
 }
 
+// WithConfigOptions applies opts, such as WithPostApply, to the config of the
+// run, including one set with WithConfig.
+func WithConfigOptions(opts ...Option) TestOption {
+	return func(tc *TestConfig) { tc.Options = append(tc.Options, opts...) }
+}
+
 func RunTestsWithOptions(t *testing.T, opts ...TestOption) {
 	tc := &TestConfig{
 		Parallel: true,
@@ -170,12 +339,23 @@ func RunTestsWithOptions(t *testing.T, opts ...TestOption) {
 	if tc.Config == nil {
 		tc.Config = NewConfig()
 	}
+	for _, opt := range tc.Options {
+		opt(tc.Config)
+	}
 
 	if tc.ExamplesPath != "" {
 		tc.Config.ExamplesPath = tc.ExamplesPath
 	}
 
-	modules := createModulesFromNames(tc.ModuleNames, getExamplesPath(tc.Config))
+	names := tc.ModuleNames
+	if len(names) > 0 {
+		resolved, err := resolveExamples(tc.Config, names)
+		if err != nil {
+			t.Fatal(redError(err.Error()))
+		}
+		names = resolved
+	}
+	modules := selectModules(t, createModulesFromNames(names, getExamplesPath(tc.Config)), tc.Config)
 	sourceType := BoolToStr(tc.UseLocal, "local", "registry")
 	var setup TestSetupFunc
 	if tc.UseLocal {
@@ -185,45 +365,163 @@ func RunTestsWithOptions(t *testing.T, opts ...TestOption) {
 }
 
 func runModuleTests(t *testing.T, modules []*Module, parallel bool, config *Config, setup TestSetupFunc, sourceType string) {
-	ctx := context.Background()
+	// An upgrade applies the registry version first, which a local source
+	// conversion has already replaced.
+	if config.Upgrade && sourceType == "local" {
+		t.Fatal(redError("-upgrade cannot be combined with -local"))
+	}
+
+	ctx := interruptContext(t)
 	results := NewTestResults()
 
+	if config.Isolate {
+		config = isolate(t, config, modules)
+	}
+
 	if setup != nil {
 		if err := setup(ctx, t, modules); err != nil {
 			t.Fatal(redError(fmt.Sprintf("Setup failed: %v", err)))
 		}
 	}
 
+	retryPolicy, err := resolveRetryPolicy(config)
+	if err != nil {
+		t.Fatal(redError(fmt.Sprintf("Failed to load retry policy: %v", err)))
+	}
 	for _, module := range modules {
-		if slices.Contains(config.ExceptionList, module.Name) {
+		if module.RetryPolicy == nil {
+			module.RetryPolicy = retryPolicy
+		}
+	}
+
+	runner := newModuleRunner(config, sourceType)
+	journal, err := NewJournal(config.JournalPath)
+	if err != nil {
+		t.Fatal(redError(fmt.Sprintf("Failed to create journal: %v", err)))
+	}
+	runner.journal = journal
+	if config.Upgrade {
+		info, err := resolveModuleInfo(config)
+		if err != nil {
+			t.Fatal(redError(fmt.Sprintf("Upgrade setup failed: %v", err)))
+		}
+		runner.upgradeInfo = info
+		runner.upgradeConverter = NewSourceConverter(NewRegistryClient())
+	}
+
+	var selected []*Module
+	for _, module := range modules {
+		if matchesAnyPattern(config.ExceptionList, module.Name) {
 			t.Logf("Skipping example %s as it is in the exception list", module.Name)
 			continue
 		}
+		selected = append(selected, module)
+	}
 
-		t.Run(module.Name, func(t *testing.T) {
-			if parallel {
-				t.Parallel()
+	// Manifests are loaded before the fixture outputs are passed, so the
+	// manifest vars win for modules passed in directly as for discovered ones.
+	if err := loadManifests(selected); err != nil {
+		t.Fatal(redError(fmt.Sprintf("Failed to load example manifest: %v", err)))
+	}
+	runner.toolchain = setupToolchain(ctx, t, config, selected)
+
+	// A plan-only run applies nothing, so it has no fixture outputs either.
+	if !config.Validate && !config.PlanOnly && len(selected) > 0 {
+		outputs := runner.setupFixture(ctx, t, retryPolicy)
+		for _, module := range selected {
+			if err := passFixtureOutputs(module, outputs); err != nil {
+				t.Fatal(redError(fmt.Sprintf("Failed to pass fixture outputs to %s: %v", module.Name, err)))
 			}
+		}
+	}
 
-			if err := module.Apply(ctx, t); err != nil {
-				t.Fail()
-			} else {
-				t.Logf("✓ Module %s applied successfully with %s source", module.Name, sourceType)
-			}
+	graph, err := newDependencyGraph(selected)
+	if err != nil {
+		t.Fatal(redError(fmt.Sprintf("Invalid example dependencies: %v", err)))
+	}
+	runner.graph = graph
 
-			if !config.SkipDestroy {
-				if err := module.Destroy(ctx, t); err != nil && !module.ApplyFailed {
-					t.Logf("Cleanup failed for module %s: %v", module.Name, err)
-				}
-			}
+	var variants map[string][]*Module
+	if len(config.TerraformVersions) > 0 {
+		toolchains, err := resolveVersionMatrix(ctx, t, config)
+		if err != nil {
+			t.Fatal(redError(fmt.Sprintf("Failed to resolve terraform versions: %v", err)))
+		}
+		var all []*Module
+		if variants, all, err = expandVersionMatrix(selected, toolchains); err != nil {
+			t.Fatal(redError(fmt.Sprintf("Invalid version matrix: %v", err)))
+		}
+		if runner.graph, err = newDependencyGraph(all); err != nil {
+			t.Fatal(redError(fmt.Sprintf("Invalid version matrix: %v", err)))
+		}
+	}
 
-			results.AddModule(module)
-		})
+	runExample := func(t *testing.T, module *Module) {
+		runner.run(ctx, t, module)
+		results.AddModule(module)
+		if module.SkipReason != "" {
+			t.Skipf("Module %s skipped: %s", module.Name, module.SkipReason)
+		}
+		if module.BlockedReason != "" {
+			t.Skipf("Module %s blocked: %s", module.Name, module.BlockedReason)
+		}
+		if module.Interrupted && !t.Failed() {
+			t.Skipf("Module %s not started: %v", module.Name, context.Cause(ctx))
+		}
+	}
+
+	runSubtest := func(t *testing.T, module *Module) {
+		graph.markStarted(module.Name)
+		if parallel {
+			t.Parallel()
+		}
+
+		if variants == nil {
+			runExample(t, module)
+			return
+		}
+		// Versions of an example share its directory, so they run in turn.
+		for _, variant := range variants[module.Name] {
+			t.Run(variant.Toolchain.Label(), func(t *testing.T) {
+				runExample(t, variant)
+			})
+		}
+	}
+
+	levels := graph.levels()
+	if !parallel || len(levels) < 2 {
+		for _, module := range graph.order {
+			t.Run(module.Name, func(t *testing.T) {
+				runSubtest(t, module)
+			})
+		}
+		graph.markNotStarted(graph.order)
+	} else {
+		// A parallel example waiting for its dependencies would hold one of
+		// the -test.parallel slots the dependencies need, so each dependency
+		// level runs as a group once the previous level has finished.
+		for i, level := range levels {
+			t.Run(fmt.Sprintf("level-%d", i), func(t *testing.T) {
+				for _, module := range level {
+					t.Run(module.Name, func(t *testing.T) {
+						runSubtest(t, module)
+					})
+				}
+			})
+			graph.markNotStarted(level)
+		}
 	}
 
 	t.Cleanup(func() {
 		modules, _ := results.GetResults()
+		t.Logf("Toolchain: %s", runner.toolchain)
 		PrintModuleSummary(t, modules)
+		if interrupted(ctx) {
+			t.Error(redError(context.Cause(ctx).Error()))
+		}
+	})
+	t.Cleanup(func() {
+		runner.destroyDeferred(ctx, t)
 	})
 }
 
@@ -270,37 +568,59 @@ func createModulesFromNames(moduleNames []string, basePath string) []*Module {
 	return modules
 }
 
-func convertModulesToLocal(ctx context.Context, t *testing.T, converter SourceConverter, moduleNames []string, exceptionList []string, moduleInfo ModuleInfo, examplesPath string) []FileRestore {
-	var allFilesToRestore []FileRestore
-
-	for _, moduleName := range moduleNames {
-		if slices.Contains(exceptionList, moduleName) {
-			continue
-		}
+func selectModules(t *testing.T, modules []*Module, config *Config) []*Module {
+	if err := loadManifests(modules); err != nil {
+		t.Fatal(redError(fmt.Sprintf("Failed to load example manifest: %v", err)))
+	}
+	selected, err := filterModulesByTags(modules, config)
+	if err != nil {
+		t.Fatal(redError(fmt.Sprintf("Failed to select examples by tags: %v", err)))
+	}
+	selected, err = filterModulesByChanges(selected, config, getExamplesPath(config))
+	if err != nil {
+		t.Fatal(redError(fmt.Sprintf("Failed to select changed examples: %v", err)))
+	}
+	return selected
+}
 
-		modulePath := filepath.Join(examplesPath, moduleName)
-		filesToRestore, err := converter.ConvertToLocal(ctx, modulePath, moduleInfo)
-		if err != nil {
-			t.Logf("Warning: Failed to convert module %s to local source: %v", moduleName, err)
-			continue
-		}
-		allFilesToRestore = append(allFilesToRestore, filesToRestore...)
+func convertModuleToLocal(ctx context.Context, t *testing.T, converter SourceConverter, moduleName, modulePath string, moduleInfo ModuleInfo) []FileRestore {
+	filesToRestore, err := converter.ConvertToLocal(ctx, modulePath, moduleInfo)
+	if err != nil {
+		t.Logf("Warning: Failed to convert module %s to local source: %v", moduleName, err)
+		return nil
 	}
+	return filesToRestore
+}
 
-	return allFilesToRestore
+func resolveModuleInfo(config *Config) (ModuleInfo, error) {
+	moduleInfo := extractModuleInfoFromRepo()
+	if moduleInfo.Name == "" || moduleInfo.Provider == "" {
+		return ModuleInfo{}, fmt.Errorf("could not determine module name and provider from repository")
+	}
+	moduleInfo.Namespace = config.Namespace
+	return moduleInfo, nil
 }
 
 func createLocalSetupFunc(config *Config) TestSetupFunc {
 	return func(ctx context.Context, t *testing.T, modules []*Module) error {
-		moduleInfo := extractModuleInfoFromRepo()
-		if moduleInfo.Name == "" || moduleInfo.Provider == "" {
-			return fmt.Errorf("could not determine module name and provider from repository")
+		moduleInfo, err := resolveModuleInfo(config)
+		if err != nil {
+			return err
 		}
-		moduleInfo.Namespace = config.Namespace
 
-		converter := NewSourceConverter(NewRegistryClient())
-		moduleNames := extractModuleNames(modules)
-		allFilesToRestore := convertModulesToLocal(ctx, t, converter, moduleNames, config.ExceptionList, moduleInfo, getExamplesPath(config))
+		// Modules are converted where they are, which is the workspace copy in
+		// an isolated run. The copy is thrown away, so nothing is reverted.
+		converter := newLocalConverter()
+		var allFilesToRestore []FileRestore
+		for _, module := range modules {
+			if matchesAnyPattern(config.ExceptionList, module.Name) {
+				continue
+			}
+			allFilesToRestore = append(allFilesToRestore, convertModuleToLocal(ctx, t, converter, module.Name, module.Path, moduleInfo)...)
+		}
+		if config.Isolate {
+			return nil
+		}
 
 		t.Cleanup(func() {
 			cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -384,4 +704,8 @@ var gitRemoteURL = func(dir string) ([]byte, error) {
 	return cmd.Output()
 }
 
+var newLocalConverter = func() SourceConverter {
+	return NewSourceConverter(NewRegistryClient())
+}
+
 var runModuleTestsFn = runModuleTests
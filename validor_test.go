@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -224,6 +225,9 @@ func TestTestConfig_Options(t *testing.T) {
 	})
 
 	t.Run("RunTestsWithOptions applies overrides", func(t *testing.T) {
+		examplesPath := t.TempDir()
+		writeFile(t, filepath.Join(examplesPath, "a", "main.tf"), "")
+
 		origRun := runModuleTestsFn
 		defer func() { runModuleTestsFn = origRun }()
 
@@ -233,21 +237,50 @@ func TestTestConfig_Options(t *testing.T) {
 			if !parallel {
 				t.Fatalf("expected parallel to be true")
 			}
-			if config.ExamplesPath != "/tmp/examples" {
+			if config.ExamplesPath != examplesPath {
 				t.Fatalf("expected examples path override, got %s", config.ExamplesPath)
 			}
+			if len(config.hooks) != 1 || config.hooks[0].phase != hookPostApply {
+				t.Fatalf("expected the post-apply hook from WithConfigOptions, got %v", config.hooks)
+			}
 		}
 
 		RunTestsWithOptions(&testing.T{},
-			WithTestExamplesPath("/tmp/examples"),
+			WithTestExamplesPath(examplesPath),
 			WithParallel(true),
 			WithModules([]string{"a"}),
+			WithConfigOptions(WithPostApply(func(ctx context.Context, t *testing.T, m *Module) error { return nil })),
 		)
 
 		if !called {
 			t.Fatalf("runModuleTests should have been invoked")
 		}
 	})
+
+	t.Run("RunTestsWithOptions rejects unknown examples", func(t *testing.T) {
+		examplesPath := t.TempDir()
+		writeFile(t, filepath.Join(examplesPath, "a", "main.tf"), "")
+
+		origRun := runModuleTestsFn
+		defer func() { runModuleTestsFn = origRun }()
+
+		called := false
+		runModuleTestsFn = func(t *testing.T, modules []*Module, parallel bool, config *Config, setup TestSetupFunc, sourceType string) {
+			called = true
+		}
+
+		mockT := &testing.T{}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			RunTestsWithOptions(mockT, WithTestExamplesPath(examplesPath), WithModules([]string{"missing"}))
+		}()
+		<-done
+
+		if called || !mockT.Failed() {
+			t.Errorf("expected an unknown example to fail the run, called = %v", called)
+		}
+	})
 }
 
 func TestSetupConfigWithOptions(t *testing.T) {
@@ -271,145 +304,119 @@ func TestSetupConfigWithOptions(t *testing.T) {
 	})
 }
 
-func TestConvertModulesToLocal(t *testing.T) {
-	tmpDir := t.TempDir()
-	examplesDir := filepath.Join(tmpDir, "examples")
-	if err := os.MkdirAll(examplesDir, 0755); err != nil {
-		t.Fatalf("Failed to create examples directory: %v", err)
-	}
-
-	moduleNames := []string{"example1", "example2"}
-	for _, modName := range moduleNames {
-		modDir := filepath.Join(examplesDir, modName)
-		if err := os.Mkdir(modDir, 0755); err != nil {
-			t.Fatalf("Failed to create module directory: %v", err)
-		}
-
-		tfContent := `
+func TestConvertModuleToLocal(t *testing.T) {
+	modDir := filepath.Join(t.TempDir(), "example1")
+	tfContent := `
 module "test" {
   source  = "cloudnationhq/mymodule/azure"
   version = "~> 1.0"
 }
 `
-		tfFile := filepath.Join(modDir, "main.tf")
-		if err := os.WriteFile(tfFile, []byte(tfContent), 0644); err != nil {
-			t.Fatalf("Failed to create terraform file: %v", err)
-		}
-	}
+	writeFile(t, filepath.Join(modDir, "main.tf"), tfContent)
 
-	client := &mockRegistryClient{latestVersion: "1.0.0"}
-	converter := NewSourceConverter(client)
+	converter := NewSourceConverter(&mockRegistryClient{latestVersion: "1.0.0"})
 	moduleInfo := ModuleInfo{
 		Name:      "mymodule",
 		Provider:  "azure",
 		Namespace: "cloudnationhq",
 	}
 
-	ctx := testContext(t)
-	mockT := &testing.T{}
-	filesToRestore := convertModulesToLocal(ctx, mockT, converter, moduleNames, []string{}, moduleInfo, examplesDir)
-
-	if len(filesToRestore) == 0 {
-		t.Error("convertModulesToLocal should return files to restore")
-	}
-
-	if len(filesToRestore) != 2 {
-		t.Errorf("Expected 2 files to restore, got %d", len(filesToRestore))
+	filesToRestore := convertModuleToLocal(testContext(t), t, converter, "example1", modDir, moduleInfo)
+	if len(filesToRestore) != 1 {
+		t.Errorf("Expected 1 file to restore, got %d", len(filesToRestore))
 	}
 }
 
-func TestConvertModulesToLocal_WithExceptions(t *testing.T) {
-	tmpDir := t.TempDir()
-	examplesDir := filepath.Join(tmpDir, "examples")
-	if err := os.MkdirAll(examplesDir, 0755); err != nil {
-		t.Fatalf("Failed to create examples directory: %v", err)
-	}
-
-	moduleNames := []string{"example1", "example2"}
-	exceptionList := []string{"example2"}
-
-	for _, modName := range moduleNames {
-		modDir := filepath.Join(examplesDir, modName)
-		if err := os.Mkdir(modDir, 0755); err != nil {
-			t.Fatalf("Failed to create module directory: %v", err)
-		}
-
-		tfContent := `
+func TestConvertModuleToLocal_CancelledContext(t *testing.T) {
+	modDir := filepath.Join(t.TempDir(), "example1")
+	tfFile := filepath.Join(modDir, "main.tf")
+	original := `
 module "test" {
   source  = "cloudnationhq/mymodule/azure"
   version = "~> 1.0"
 }
 `
-		tfFile := filepath.Join(modDir, "main.tf")
-		if err := os.WriteFile(tfFile, []byte(tfContent), 0644); err != nil {
-			t.Fatalf("Failed to create terraform file: %v", err)
-		}
-	}
+	writeFile(t, tfFile, original)
 
-	client := &mockRegistryClient{latestVersion: "1.0.0"}
-	converter := NewSourceConverter(client)
+	converter := NewSourceConverter(&mockRegistryClient{latestVersion: "1.0.0"})
 	moduleInfo := ModuleInfo{
 		Name:      "mymodule",
 		Provider:  "azure",
 		Namespace: "cloudnationhq",
 	}
 
-	ctx := testContext(t)
-	mockT := &testing.T{}
-	filesToRestore := convertModulesToLocal(ctx, mockT, converter, moduleNames, exceptionList, moduleInfo, examplesDir)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	filesToRestore := convertModuleToLocal(ctx, t, converter, "example1", modDir, moduleInfo)
+	if len(filesToRestore) != 0 {
+		t.Fatalf("expected no files to restore when context is cancelled, got %d", len(filesToRestore))
+	}
 
-	if len(filesToRestore) != 1 {
-		t.Errorf("Expected 1 file to restore (excluding exception), got %d", len(filesToRestore))
+	content, err := os.ReadFile(tfFile)
+	if err != nil {
+		t.Fatalf("failed to read terraform file: %v", err)
+	}
+	if string(content) != original {
+		t.Fatalf("terraform file should remain unchanged on cancellation")
 	}
 }
 
-func TestConvertModulesToLocal_CancelledContext(t *testing.T) {
-	tmpDir := t.TempDir()
-	examplesDir := filepath.Join(tmpDir, "examples")
-	if err := os.MkdirAll(examplesDir, 0755); err != nil {
-		t.Fatalf("Failed to create examples directory: %v", err)
+func TestCreateLocalSetupFunc(t *testing.T) {
+	origGit := gitRemoteURL
+	origConverter := newLocalConverter
+	defer func() {
+		gitRemoteURL = origGit
+		newLocalConverter = origConverter
+	}()
+	gitRemoteURL = func(dir string) ([]byte, error) {
+		return []byte("git@github.com:cloudnationhq/terraform-azure-mymodule.git\n"), nil
 	}
-
-	modDir := filepath.Join(examplesDir, "example1")
-	if err := os.Mkdir(modDir, 0755); err != nil {
-		t.Fatalf("Failed to create module directory: %v", err)
+	newLocalConverter = func() SourceConverter {
+		return NewSourceConverter(&mockRegistryClient{latestVersion: "1.0.0"})
 	}
 
-	tfFile := filepath.Join(modDir, "main.tf")
+	examplesDir := filepath.Join(t.TempDir(), "examples")
 	original := `
 module "test" {
   source  = "cloudnationhq/mymodule/azure"
   version = "~> 1.0"
 }
 `
-	if err := os.WriteFile(tfFile, []byte(original), 0644); err != nil {
-		t.Fatalf("Failed to create terraform file: %v", err)
-	}
-
-	client := &mockRegistryClient{latestVersion: "1.0.0"}
-	converter := NewSourceConverter(client)
-	moduleInfo := ModuleInfo{
-		Name:      "mymodule",
-		Provider:  "azure",
-		Namespace: "cloudnationhq",
+	for _, name := range []string{"example1", "example2"} {
+		writeFile(t, filepath.Join(examplesDir, name, "main.tf"), original)
 	}
+	modules := createModulesFromNames([]string{"example1", "example2"}, examplesDir)
+	config := NewConfig(WithNamespace("cloudnationhq"))
+	config.ExceptionList = []string{"example2"}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	cancel()
-
-	mockT := &testing.T{}
-	filesToRestore := convertModulesToLocal(ctx, mockT, converter, []string{"example1"}, []string{}, moduleInfo, examplesDir)
+	t.Run("setup", func(t *testing.T) {
+		if err := createLocalSetupFunc(config)(testContext(t), t, modules); err != nil {
+			t.Fatalf("setup error = %v", err)
+		}
 
-	if len(filesToRestore) != 0 {
-		t.Fatalf("expected no files to restore when context is cancelled, got %d", len(filesToRestore))
-	}
+		converted, err := os.ReadFile(filepath.Join(examplesDir, "example1", "main.tf"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(converted) == original {
+			t.Error("expected example1 to be converted to a local source")
+		}
+		excepted, err := os.ReadFile(filepath.Join(examplesDir, "example2", "main.tf"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(excepted) != original {
+			t.Error("expected example2 in the exception list to stay unchanged")
+		}
+	})
 
-	content, err := os.ReadFile(tfFile)
+	reverted, err := os.ReadFile(filepath.Join(examplesDir, "example1", "main.tf"))
 	if err != nil {
-		t.Fatalf("failed to read terraform file: %v", err)
+		t.Fatal(err)
 	}
-	if string(content) != original {
-		t.Fatalf("terraform file should remain unchanged on cancellation")
+	if !strings.Contains(string(reverted), `source  = "cloudnationhq/mymodule/azure"`) {
+		t.Errorf("expected example1 to be reverted to the registry source, got %s", reverted)
 	}
 }
 